@@ -3,3 +3,11 @@ package api
 func (c *Cluster) GetName() string {
 	return c.Name
 }
+
+func (t *ClusterTemplate) GetName() string {
+	return t.Name
+}
+
+func (r *Registry) GetName() string {
+	return r.Name
+}
@@ -80,6 +80,11 @@ type SimpleConfigOptionsK3s struct {
 	NodeLabels []LabelWithNodeFilters  `mapstructure:"nodeLabels" yaml:"nodeLabels,omitempty" json:"nodeLabels,omitempty"`
 }
 
+type SimpleConfigRegistries struct {
+	Use    []string `mapstructure:"use" yaml:"use,omitempty" json:"use,omitempty"`
+	Config string   `mapstructure:"config" yaml:"config,omitempty" json:"config,omitempty"` // registries.yaml (k3s config for containerd registry override)
+}
+
 type SimpleConfigHostAlias struct {
 	IP        string   `mapstructure:"ip" yaml:"ip" json:"ip"`
 	Hostnames []string `mapstructure:"hostnames" yaml:"hostnames" json:"hostnames"`
@@ -99,6 +104,7 @@ type SimpleConfig struct {
 	Volumes      []VolumeWithNodeFilters `mapstructure:"volumes" yaml:"volumes,omitempty" json:"volumes,omitempty"`
 	Ports        []PortWithNodeFilters   `mapstructure:"ports" yaml:"ports,omitempty" json:"ports,omitempty"`
 	Options      SimpleConfigOptions     `mapstructure:"options" yaml:"options,omitempty" json:"options,omitempty"`
+	Registries   SimpleConfigRegistries  `mapstructure:"registries" yaml:"registries,omitempty" json:"registries,omitempty"`
 	Env          []EnvVarWithNodeFilters `mapstructure:"env" yaml:"env,omitempty" json:"env,omitempty"`
 	HostAliases  []SimpleConfigHostAlias `mapstructure:"hostAliases" yaml:"hostAliases,omitempty" json:"hostAliases,omitempty"`
 }
@@ -129,6 +129,7 @@ func (in *SimpleConfig) DeepCopyInto(out *SimpleConfig) {
 		}
 	}
 	in.Options.DeepCopyInto(&out.Options)
+	in.Registries.DeepCopyInto(&out.Registries)
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make([]EnvVarWithNodeFilters, len(*in))
@@ -304,6 +305,27 @@ func (in *SimpleConfigOptionsRuntime) DeepCopy() *SimpleConfigOptionsRuntime {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SimpleConfigRegistries) DeepCopyInto(out *SimpleConfigRegistries) {
+	*out = *in
+	if in.Use != nil {
+		in, out := &in.Use, &out.Use
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimpleConfigRegistries.
+func (in *SimpleConfigRegistries) DeepCopy() *SimpleConfigRegistries {
+	if in == nil {
+		return nil
+	}
+	out := new(SimpleConfigRegistries)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SimpleExposureOpts) DeepCopyInto(out *SimpleExposureOpts) {
 	*out = *in
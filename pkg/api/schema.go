@@ -24,3 +24,33 @@ func (obj *ClusterList) GroupVersionKind() schema.GroupVersionKind {
 }
 
 var _ runtime.Object = &ClusterList{}
+
+func (obj *ClusterTemplate) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *ClusterTemplate) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *ClusterTemplate) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &ClusterTemplate{}
+
+func (obj *Registry) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *Registry) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *Registry) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &Registry{}
+
+func (obj *RegistryList) GetObjectKind() schema.ObjectKind { return obj }
+func (obj *RegistryList) SetGroupVersionKind(gvk schema.GroupVersionKind) {
+	obj.APIVersion, obj.Kind = gvk.ToAPIVersionAndKind()
+}
+func (obj *RegistryList) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind)
+}
+
+var _ runtime.Object = &RegistryList{}
@@ -0,0 +1,25 @@
+package api
+
+// Copies a template body, as decoded from YAML or JSON.
+//
+// deepcopy-gen can't copy interface{} values, so ClusterTemplate's
+// generated DeepCopyInto calls this by hand.
+func deepCopyTemplateValue(in interface{}) interface{} {
+	switch in := in.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(in))
+		for k, v := range in {
+			out[k] = deepCopyTemplateValue(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(in))
+		for i, v := range in {
+			out[i] = deepCopyTemplateValue(v)
+		}
+		return out
+	default:
+		// Scalars (strings, numbers, bools, and nil) are immutable.
+		return in
+	}
+}
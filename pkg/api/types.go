@@ -25,6 +25,20 @@ type Cluster struct {
 	// The name of the tool used to create this cluster.
 	Product string `json:"product,omitempty" yaml:"product,omitempty"`
 
+	// Labels to attach to the cluster, for selecting clusters
+	// with `yap get -l` and `yap delete -l`.
+	//
+	// Stored in the cluster itself, alongside the rest of the spec.
+	//
+	// A top-level field, like name, because a Cluster has no metadata.
+	// Configs may set metadata.labels instead.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Accepted for configs written like Kubernetes objects. Yap moves
+	// metadata.labels to labels when it reads a config, so this is
+	// always empty after parsing.
+	Metadata *ClusterMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
 	// Make sure that the cluster has access to at least this many
 	// CPUs. This is mostly helpful for ensuring that your Docker/Lima
 	// VM has enough CPU. If yap can't guarantee this many
@@ -41,6 +55,15 @@ type Cluster struct {
 	// Not all cluster products allow you to customize this.
 	KubernetesVersion string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
 
+	// The node image to run. Only applicable for clusters with product: kind.
+	//
+	// Takes precedence over kubernetesVersion when picking an image. Useful
+	// when running a version of kind that yap doesn't have images for.
+	//
+	// Example:
+	// kindest/node:v1.27.3
+	NodeImage string `json:"nodeImage,omitempty" yaml:"nodeImage,omitempty"`
+
 	// The Kind cluster config. Only applicable for clusters with product: kind.
 	//
 	// Full documentation at:
@@ -60,6 +83,22 @@ type Cluster struct {
 	// The Colima cluster config. Only applicable for clusters with product: colima.
 	Colima *ColimaCluster `json:"colima,omitempty" yaml:"colima,omitempty"`
 
+	// The EKS cluster config. Only applicable for clusters with product: eks.
+	EKS *EKSCluster `json:"eks,omitempty" yaml:"eks,omitempty"`
+
+	// The GKE cluster config. Only applicable for clusters with product: gke.
+	GKE *GKECluster `json:"gke,omitempty" yaml:"gke,omitempty"`
+
+	// The AKS cluster config. Only applicable for clusters with product: aks.
+	AKS *AKSCluster `json:"aks,omitempty" yaml:"aks,omitempty"`
+
+	// The name of a registry.
+	//
+	// If the registry doesn't exist, yap will create one.
+	//
+	// Not supported on all cluster products.
+	Registry string `json:"registry,omitempty" yaml:"registry,omitempty"`
+
 	// Most recently observed status of the cluster.
 	// Populated by the system.
 	// Read-only.
@@ -86,8 +125,159 @@ type ClusterStatus struct {
 	// v1.19.3-34+fa32ff1c160058
 	KubernetesVersion string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
 
+	// The host and port of the cluster's Kubernetes API server,
+	// as recorded in the kubeconfig.
+	//
+	// Examples:
+	// 127.0.0.1:6443
+	// my-aks-dns-1a2b3c4d.hcp.eastus.azmk8s.io:443
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+
+	// Whether the cluster is running, stopped, or paused.
+	//
+	// Empty if we couldn't tell.
+	Phase ClusterPhase `json:"phase,omitempty" yaml:"phase,omitempty"`
+
 	// Populated when we encounter an error reading the cluster status.
 	Error string `json:"error,omitempty"`
+
+	// The latest observations of the cluster's state.
+	//
+	// Unlike Error, each condition tracks one aspect of the cluster,
+	// so that tools can tell an unreachable apiserver apart from
+	// a node that's still starting.
+	Conditions []ClusterCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+
+	// A summary of the nodes in the cluster.
+	Nodes []ClusterNode `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+
+	// Describes the local registry that the cluster is connected to,
+	// as published in the kube-public/local-registry-hosting ConfigMap.
+	LocalRegistryHosting *LocalRegistryHostingV1 `json:"localRegistryHosting,omitempty" yaml:"localRegistryHosting,omitempty"`
+}
+
+// ClusterPhase is a coarse summary of whether the cluster is running.
+type ClusterPhase string
+
+const (
+	ClusterPhaseRunning ClusterPhase = "Running"
+
+	// The cluster exists, but its machines or containers are shut down.
+	// Start it with `yap start cluster`.
+	ClusterPhaseStopped ClusterPhase = "Stopped"
+
+	// The cluster's processes are frozen, but still hold their memory.
+	// Start it with `yap start cluster`.
+	ClusterPhasePaused ClusterPhase = "Paused"
+)
+
+// ClusterConditionType is a kind of observation about a cluster.
+type ClusterConditionType string
+
+const (
+	// The Kubernetes API server answered a health check.
+	ClusterConditionAPIServerReachable ClusterConditionType = "APIServerReachable"
+
+	// Every node in the cluster reports Ready.
+	ClusterConditionNodesReady ClusterConditionType = "NodesReady"
+
+	// The cluster has a copy of the spec it was created with,
+	// in the kube-public/yap-cluster-spec ConfigMap.
+	ClusterConditionSpecRecorded ClusterConditionType = "SpecRecorded"
+
+	// The machine running the cluster (e.g., the Docker VM) responded.
+	ClusterConditionMachineHealthy ClusterConditionType = "MachineHealthy"
+
+	// The cluster is ready to run workloads: the apiserver and nodes are
+	// healthy, DNS is available, and the default StorageClass and
+	// ServiceAccount exist.
+	//
+	// These checks are slower, so only `yap wait` reports this condition.
+	ClusterConditionReady ClusterConditionType = "Ready"
+)
+
+// ClusterCondition describes one aspect of the cluster's state.
+//
+// Modeled after the conditions on Kubernetes objects.
+type ClusterCondition struct {
+	// The kind of condition.
+	Type ClusterConditionType `json:"type" yaml:"type"`
+
+	// One of True, False, or Unknown.
+	Status metav1.ConditionStatus `json:"status" yaml:"status"`
+
+	// The last time the condition changed status, if known.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" yaml:"lastTransitionTime,omitempty"`
+
+	// A one-word, CamelCase reason for the condition's status.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// A human-readable description of the condition.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// ClusterNode summarizes a Kubernetes node.
+type ClusterNode struct {
+	// The node name.
+	Name string `json:"name" yaml:"name"`
+
+	// The node's roles, from its node-role.kubernetes.io labels,
+	// separated by commas.
+	//
+	// Examples:
+	// control-plane
+	// control-plane,master
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+
+	// Whether the node reports Ready.
+	Ready bool `json:"ready" yaml:"ready"`
+
+	// The version of the kubelet on the node.
+	//
+	// Example:
+	// v1.27.3
+	KubeletVersion string `json:"kubeletVersion,omitempty" yaml:"kubeletVersion,omitempty"`
+
+	// The container runtime on the node.
+	//
+	// Example:
+	// containerd://1.7.1
+	ContainerRuntime string `json:"containerRuntime,omitempty" yaml:"containerRuntime,omitempty"`
+}
+
+// LocalRegistryHostingV1 describes a local registry that developer tools can
+// connect to. A local registry allows clients to load images into the local
+// cluster by pushing to this registry.
+//
+// Matches the schema of the ConfigMap described in KEP-1755:
+// https://github.com/kubernetes/enhancements/tree/master/keps/sig-cluster-lifecycle/generic/1755-communicating-a-local-registry
+type LocalRegistryHostingV1 struct {
+	// Host documents the host (hostname and port) of the registry, as seen from
+	// outside the cluster.
+	//
+	// This is the registry host that tools outside the cluster should push images
+	// to.
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+
+	// HostFromClusterNetwork documents the host (hostname and port) of the
+	// registry, as seen from networking inside the container pods.
+	//
+	// This is the registry host that tools running on pods inside the cluster
+	// should push images to. If not set, then tools inside the cluster should
+	// assume the local registry is not available to them.
+	HostFromClusterNetwork string `json:"hostFromClusterNetwork,omitempty" yaml:"hostFromClusterNetwork,omitempty"`
+
+	// HostFromContainerRuntime documents the host (hostname and port) of the
+	// registry, as seen from the cluster's container runtime.
+	//
+	// When tools apply Kubernetes objects to the cluster, this host should be
+	// used for image name fields. If not set, users of this field should use the
+	// value of Host instead.
+	HostFromContainerRuntime string `json:"hostFromContainerRuntime,omitempty" yaml:"hostFromContainerRuntime,omitempty"`
+
+	// Help contains a URL pointing to documentation for users on how to set
+	// up and configure a local registry.
+	Help string `json:"help,omitempty" yaml:"help,omitempty"`
 }
 
 // MinikubeCluster describes minikube-specific options for starting a cluster.
@@ -139,16 +329,217 @@ type ColimaCluster struct {
 	// Unstructured flags to pass to colima on `colima start`.
 	StartFlags []string `json:"startFlags,omitempty" yaml:"startFlags,omitempty"`
 
-	// MetalLB address pool
+	// The memory of the colima VM, in GiB. Defaults to colima's default.
+	Memory int `json:"memory,omitempty" yaml:"memory,omitempty"`
+
+	// The address pool that MetalLB assigns to LoadBalancer services.
+	// Defaults to 192.168.106.240/29.
 	MetalLbCidr string `json:"metallbCidr,omitempty" yaml:"metallbCidr,omitempty"`
 }
 
+// EKSCluster describes options for creating a cluster on Amazon EKS with eksctl.
+//
+// Options in this struct, when possible, should match the flags
+// to `eksctl create cluster`.
+type EKSCluster struct {
+	// The AWS region to create the cluster in, e.g., us-west-2.
+	// Defaults to the region in your AWS config.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// The number of worker nodes in the default node group.
+	NodeGroupSize int `json:"nodeGroupSize,omitempty" yaml:"nodeGroupSize,omitempty"`
+
+	// The EC2 instance type of the worker nodes, e.g., m5.large.
+	InstanceType string `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+
+	// The EKS Kubernetes version, e.g., 1.27.
+	//
+	// EKS only lets you choose a major and minor version, so this
+	// is separate from the top-level kubernetesVersion field.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// GKECluster describes options for creating a cluster on Google Kubernetes
+// Engine with gcloud.
+//
+// Options in this struct, when possible, should match the flags
+// to `gcloud container clusters create`.
+type GKECluster struct {
+	// The Google Cloud project to create the cluster in.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+
+	// The compute zone of a zonal cluster, e.g., us-central1-b.
+	// Only one of zone or region may be set.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// The compute region of a regional cluster, e.g., us-central1.
+	// Only one of zone or region may be set.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// The machine type of the nodes, e.g., e2-standard-4.
+	MachineType string `json:"machineType,omitempty" yaml:"machineType,omitempty"`
+
+	// The number of nodes in the default node pool.
+	NodeCount int `json:"nodeCount,omitempty" yaml:"nodeCount,omitempty"`
+
+	// The release channel of the cluster: rapid, regular, or stable.
+	ReleaseChannel string `json:"releaseChannel,omitempty" yaml:"releaseChannel,omitempty"`
+}
+
+// AKSCluster describes options for creating a cluster on Azure Kubernetes
+// Service with the az CLI.
+//
+// Options in this struct, when possible, should match the flags
+// to `az aks create`.
+type AKSCluster struct {
+	// The Azure resource group of the cluster. Required.
+	ResourceGroup string `json:"resourceGroup,omitempty" yaml:"resourceGroup,omitempty"`
+
+	// The Azure location to create the cluster in, e.g., eastus.
+	// Defaults to the location of the resource group.
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+
+	// The number of nodes in the default node pool.
+	NodeCount int `json:"nodeCount,omitempty" yaml:"nodeCount,omitempty"`
+
+	// The VM size of the nodes, e.g., Standard_DS2_v2.
+	NodeVMSize string `json:"nodeVMSize,omitempty" yaml:"nodeVMSize,omitempty"`
+}
+
 // ClusterList is a list of Clusters.
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type ClusterList struct {
-	TypeMeta `json:",inline"`
+	TypeMeta `json:",inline" yaml:",inline"`
 
 	// List of clusters.
 	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md
-	Items []Cluster `json:"items" protobuf:"bytes,2,rep,name=items"`
+	Items []Cluster `json:"items" yaml:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// ClusterMetadata is the subset of Kubernetes object metadata
+// that a Cluster config may set.
+type ClusterMetadata struct {
+	// The same as the Cluster's labels.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// ClusterTemplate is a reusable cluster config, with parameters that
+// users fill in when they create a cluster from it.
+//
+// Create a cluster from a template with:
+//
+//	yap create cluster --template team-kind --set workers=2
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ClusterTemplate struct {
+	TypeMeta `yaml:",inline"`
+
+	// The template name. Used to find the template with `--template NAME`.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// A human-readable description of the clusters this template creates.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// The parameters that users may set with `--set NAME=VALUE`.
+	Parameters []ClusterTemplateParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+
+	// The Cluster to create, in the same format as `kind: Cluster`
+	// (without the apiVersion and kind).
+	//
+	// Any string may refer to a parameter as $(NAME). When a value is only
+	// a parameter reference, the parameter value keeps its YAML type, so
+	// `minCPUs: $(cpus)` renders to a number. Use $$(NAME) for a literal $(NAME).
+	//
+	// A list item with the key `yap.pseudonator.io/repeat` is repeated
+	// that many times, with the key removed. For example:
+	//
+	//	nodes:
+	//	- role: control-plane
+	//	- role: worker
+	//	  yap.pseudonator.io/repeat: $(workers)
+	Template map[string]interface{} `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+// ClusterTemplateParameter describes a parameter of a ClusterTemplate.
+type ClusterTemplateParameter struct {
+	// The name that the template refers to with $(NAME).
+	Name string `json:"name" yaml:"name"`
+
+	// A human-readable description of the parameter.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// The value to use when the user doesn't set one.
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// When true, the user must set a value.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// Registry contains registry configuration.
+//
+// Currently designed for local registries on the host machine, but
+// may eventually expand to support remote registries.
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type Registry struct {
+	TypeMeta `yaml:",inline"`
+
+	// The registry name. Get/set from the Docker container name.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// The desired host port. Set to 0 to choose a random port.
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+
+	// The desired listen address on the host. Defaults to 127.0.0.1.
+	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
+
+	// Labels to attach to the registry container.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Most recently observed status of the registry.
+	// Populated by the system.
+	// Read-only.
+	Status RegistryStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
+type RegistryStatus struct {
+	// When the registry was first created.
+	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty" yaml:"creationTimestamp,omitempty"`
+
+	// The public port that the registry is listening on on the host machine.
+	HostPort int `json:"hostPort,omitempty" yaml:"hostPort,omitempty"`
+
+	// The address that the registry is listening on on the host machine.
+	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
+
+	// The private port that the registry is listening on inside the registry
+	// network.
+	//
+	// We try to make this not configurable, because there's no real
+	// reason not to use the default registry port 5000.
+	ContainerPort int `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
+
+	// The IP address of the registry on the default bridge network.
+	IPAddress string `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
+
+	// Networks that the registry container is connected to.
+	Networks []string `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	// The ID of the container in Docker.
+	ContainerID string `json:"containerId,omitempty" yaml:"containerId,omitempty"`
+
+	// Current state of the container, as reported by Docker (e.g., running, exited).
+	State string `json:"state,omitempty" yaml:"state,omitempty"`
+
+	// Labels attached to the running container.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// RegistryList is a list of Registrys.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type RegistryList struct {
+	TypeMeta `json:",inline"`
+
+	// List of registries.
+	Items []Registry `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
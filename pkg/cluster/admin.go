@@ -15,7 +15,13 @@ type Admin interface {
 	//
 	// Make a best effort attempt to delete any resources that might block creation
 	// of the cluster.
-	Create(ctx context.Context, desired *api.Cluster) error
+	//
+	// The registry is optional. When set, the cluster should be configured
+	// to pull images from it.
+	Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error
+
+	// Infers the LocalRegistryHostingV1 that this admin will try to configure.
+	LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error)
 
 	Delete(ctx context.Context, config *api.Cluster) error
 }
@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+
+	"github.com/pseudonator/yap/pkg/api"
+	cexec "github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+// aksAdmin uses the az CLI to create and delete clusters on
+// Azure Kubernetes Service.
+type aksAdmin struct {
+	iostreams genericclioptions.IOStreams
+	runner    cexec.CmdRunner
+}
+
+func newAKSAdmin(iostreams genericclioptions.IOStreams, runner cexec.CmdRunner) *aksAdmin {
+	return &aksAdmin{
+		iostreams: iostreams,
+		runner:    runner,
+	}
+}
+
+func (a *aksAdmin) EnsureInstalled(ctx context.Context) error {
+	_, err := exec.LookPath("az")
+	if err != nil {
+		return fmt.Errorf("az not installed. Please install the Azure CLI with these instructions: https://learn.microsoft.com/cli/azure/install-azure-cli")
+	}
+	return nil
+}
+
+func (a *aksAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
+	klog.V(3).Infof("Creating cluster with config:\n%+v\n---\n", desired)
+
+	if registry != nil {
+		return fmt.Errorf("yap currently does not support connecting a registry to aks")
+	}
+
+	resourceGroup := aksResourceGroup(desired)
+	if resourceGroup == "" {
+		return fmt.Errorf("aks clusters must specify a resourceGroup")
+	}
+
+	args := []string{
+		"aks", "create",
+		"--resource-group", resourceGroup,
+		"--name", desired.Name,
+	}
+
+	if desired.AKS.Location != "" {
+		args = append(args, "--location", desired.AKS.Location)
+	}
+	if desired.AKS.NodeCount != 0 {
+		args = append(args, "--node-count", strconv.Itoa(desired.AKS.NodeCount))
+	}
+	if desired.AKS.NodeVMSize != "" {
+		args = append(args, "--node-vm-size", desired.AKS.NodeVMSize)
+	}
+	if desired.KubernetesVersion != "" {
+		args = append(args, "--kubernetes-version", strings.TrimPrefix(desired.KubernetesVersion, "v"))
+	}
+
+	args = append(args, "--generate-ssh-keys")
+
+	in := strings.NewReader("")
+	err := a.runner.RunIO(ctx,
+		genericclioptions.IOStreams{In: in, Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
+		"az", args...)
+	if err != nil {
+		return errors.Wrap(err, "creating aks cluster")
+	}
+
+	err = a.runner.RunIO(ctx,
+		genericclioptions.IOStreams{In: strings.NewReader(""), Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
+		"az", "aks", "get-credentials",
+		"--resource-group", resourceGroup,
+		"--name", desired.Name,
+		"--context", desired.Name,
+		"--overwrite-existing")
+	if err != nil {
+		return errors.Wrap(err, "writing aks kubeconfig")
+	}
+	return nil
+}
+
+func (a *aksAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return nil, fmt.Errorf("yap currently does not support connecting a registry to aks")
+}
+
+func (a *aksAdmin) Delete(ctx context.Context, config *api.Cluster) error {
+	// The resource group isn't in the kubeconfig, so we can only
+	// delete clusters where yap has recorded the spec.
+	resourceGroup := aksResourceGroup(config)
+	if resourceGroup == "" {
+		return fmt.Errorf("cannot determine the resource group of aks cluster %s. Delete it with `az aks delete`", config.Name)
+	}
+
+	err := a.runner.RunIO(ctx, a.iostreams,
+		"az", "aks", "delete",
+		"--resource-group", resourceGroup,
+		"--name", config.Name,
+		"--yes")
+	if err != nil {
+		return errors.Wrap(err, "deleting aks cluster")
+	}
+	return nil
+}
+
+func aksResourceGroup(cluster *api.Cluster) string {
+	if cluster.AKS == nil {
+		return ""
+	}
+	return cluster.AKS.ResourceGroup
+}
@@ -0,0 +1,107 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+func TestAKSCreateFlags(t *testing.T) {
+	f := newAKSFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{
+		Name:              "my-aks",
+		KubernetesVersion: "v1.27.3",
+		AKS: &api.AKSCluster{
+			ResourceGroup: "dev",
+			Location:      "eastus",
+			NodeCount:     2,
+			NodeVMSize:    "Standard_DS2_v2",
+		},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		[]string{
+			"az", "aks", "create",
+			"--resource-group", "dev",
+			"--name", "my-aks",
+			"--location", "eastus",
+			"--node-count", "2",
+			"--node-vm-size", "Standard_DS2_v2",
+			"--kubernetes-version", "1.27.3",
+			"--generate-ssh-keys",
+		},
+		[]string{
+			"az", "aks", "get-credentials",
+			"--resource-group", "dev",
+			"--name", "my-aks",
+			"--context", "my-aks",
+			"--overwrite-existing",
+		},
+	}, f.calls)
+}
+
+func TestAKSCreateMissingResourceGroup(t *testing.T) {
+	f := newAKSFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{Name: "my-aks"}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must specify a resourceGroup")
+	}
+	assert.Empty(t, f.calls)
+}
+
+func TestAKSDelete(t *testing.T) {
+	f := newAKSFixture()
+	err := f.a.Delete(context.Background(), &api.Cluster{
+		Name: "my-aks",
+		AKS:  &api.AKSCluster{ResourceGroup: "dev"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"az", "aks", "delete", "--resource-group", "dev", "--name", "my-aks", "--yes",
+	}, f.runner.LastArgs)
+}
+
+func TestAKSCanReconcileK8sVersion(t *testing.T) {
+	c := &Controller{}
+	for _, tc := range []struct {
+		desired string
+		actual  string
+		ok      bool
+	}{
+		{"1.27", "v1.27.3", true},
+		{"1.27.3", "v1.27.3", true},
+		{"v1.27.3", "v1.27.3", true},
+		{"1.27.2", "v1.27.3", false},
+		{"1.28", "v1.27.3", false},
+		{"1.2", "v1.27.3", false},
+	} {
+		desired := &api.Cluster{Product: "aks", KubernetesVersion: tc.desired}
+		existing := &api.Cluster{Status: api.ClusterStatus{KubernetesVersion: tc.actual}}
+		assert.Equal(t, tc.ok, c.canReconcileK8sVersion(context.Background(), desired, existing),
+			"desired %s, actual %s", tc.desired, tc.actual)
+	}
+}
+
+type aksFixture struct {
+	runner *exec.FakeCmdRunner
+	calls  [][]string
+	a      *aksAdmin
+}
+
+func newAKSFixture() *aksFixture {
+	f := &aksFixture{}
+	iostreams := genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}
+	f.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		f.calls = append(f.calls, argv)
+		return ""
+	})
+	f.a = newAKSAdmin(iostreams, f.runner)
+	return f
+}
@@ -16,6 +16,10 @@ import (
 
 const (
 	cmdName = "colima"
+
+	// The default address pool for MetalLB load balancers. Colima VMs
+	// get addresses on 192.168.106.0/24, so we take a small slice at the top.
+	defaultColimaMetalLbCidr = "192.168.106.240/29"
 )
 
 type colimaAdmin struct {
@@ -38,9 +42,13 @@ func (a *colimaAdmin) EnsureInstalled(ctx context.Context) error {
 	return nil
 }
 
-func (a *colimaAdmin) Create(ctx context.Context, desired *api.Cluster) error {
+func (a *colimaAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
 	klog.V(3).Infof("Creating cluster with config:\n%+v\n---\n", desired)
 
+	if registry != nil {
+		return fmt.Errorf("yap currently does not support connecting a registry to colima")
+	}
+
 	clusterName := desired.Name
 
 	containerRuntime := "containerd"
@@ -48,6 +56,11 @@ func (a *colimaAdmin) Create(ctx context.Context, desired *api.Cluster) error {
 		containerRuntime = desired.Colima.ContainerRuntime
 	}
 
+	metalLbCidr := defaultColimaMetalLbCidr
+	if desired.Colima != nil && desired.Colima.MetalLbCidr != "" {
+		metalLbCidr = desired.Colima.MetalLbCidr
+	}
+
 	args := []string{
 		"start",
 	}
@@ -64,12 +77,15 @@ func (a *colimaAdmin) Create(ctx context.Context, desired *api.Cluster) error {
 		// Disabled traefik (https://docs.k3s.io/networking#service-load-balancer)
 		fmt.Sprintf("--kubernetes-disable=servicelb,traefik"),
 		"--install-metallb",
-		fmt.Sprintf("--metallb-address-pool=%s", desired.Colima.MetalLbCidr),
+		fmt.Sprintf("--metallb-address-pool=%s", metalLbCidr),
 	)
 
 	if desired.MinCPUs != 0 {
 		args = append(args, fmt.Sprintf("--cpu=%d", desired.MinCPUs))
 	}
+	if desired.Colima != nil && desired.Colima.Memory != 0 {
+		args = append(args, fmt.Sprintf("--memory=%d", desired.Colima.Memory))
+	}
 
 	if desired.KubernetesVersion != "" {
 		args = append(args, "--kubernetes-version", desired.KubernetesVersion)
@@ -87,6 +103,10 @@ func (a *colimaAdmin) Create(ctx context.Context, desired *api.Cluster) error {
 	return nil
 }
 
+func (a *colimaAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return nil, fmt.Errorf("yap currently does not support connecting a registry to colima")
+}
+
 func (a *colimaAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	err := a.runner.RunIO(ctx, a.iostreams, cmdName, "delete", "-p", config.Name)
 	if err != nil {
@@ -94,3 +114,33 @@ func (a *colimaAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	}
 	return nil
 }
+
+// The parts of the colima config that we can't change without
+// recreating the cluster.
+//
+// The VM size can change with a restart, so we leave it out. Clusters
+// recorded before we filled in the default MetalLB address pool get it.
+func colimaRecreateConfig(config *api.ColimaCluster) *api.ColimaCluster {
+	result := &api.ColimaCluster{}
+	if config != nil {
+		result = config.DeepCopy()
+	}
+	result.Memory = 0
+	if result.MetalLbCidr == "" {
+		result.MetalLbCidr = defaultColimaMetalLbCidr
+	}
+	return result
+}
+
+// Whether the desired VM memory differs from what the cluster
+// was created with.
+func colimaMemoryChanged(desired, existing *api.Cluster) bool {
+	if desired.Colima == nil || desired.Colima.Memory == 0 {
+		return false
+	}
+	existingMemory := 0
+	if existing.Colima != nil {
+		existingMemory = existing.Colima.Memory
+	}
+	return desired.Colima.Memory != existingMemory
+}
@@ -27,7 +27,7 @@ func TestColimaStartFlags(t *testing.T) {
 			StartFlags:       []string{"--foo"},
 			MetalLbCidr:      MetalLbCidr,
 		},
-	})
+	}, nil)
 	require.NoError(t, err)
 	assert.Equal(t, []string{
 		"colima", "start",
@@ -41,6 +41,35 @@ func TestColimaStartFlags(t *testing.T) {
 	}, f.runner.LastArgs)
 }
 
+func TestColimaStartFlagsDefaults(t *testing.T) {
+	f := newColimaFixture()
+	ctx := context.Background()
+	err := f.a.Create(ctx, &api.Cluster{
+		Name:    "colima-dev",
+		MinCPUs: 4,
+		Colima:  &api.ColimaCluster{Memory: 8},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"colima", "start",
+		"--profile=colima-dev",
+		"--kubernetes",
+		"--runtime=containerd",
+		"--kubernetes-disable=servicelb,traefik",
+		"--install-metallb",
+		"--metallb-address-pool=192.168.106.240/29",
+		"--cpu=4",
+		"--memory=8",
+	}, f.runner.LastArgs)
+}
+
+func TestColimaStartFlagsNoConfig(t *testing.T) {
+	f := newColimaFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{Name: "colima"}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, f.runner.LastArgs, "--metallb-address-pool=192.168.106.240/29")
+}
+
 type colimaFixture struct {
 	runner *exec.FakeCmdRunner
 	a      *colimaAdmin
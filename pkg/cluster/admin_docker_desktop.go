@@ -0,0 +1,81 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// dockerDesktopAdmin manipulates the Kubernetes cluster built into
+// Docker Desktop, by toggling the Docker Desktop settings.
+//
+// There's only one Docker Desktop cluster per machine, so this admin
+// shares the Docker Desktop client with the dockerMachine.
+//
+// Only works with a local Docker Desktop. The controller checks
+// DOCKER_HOST before creating this admin.
+type dockerDesktopAdmin struct {
+	iostreams genericclioptions.IOStreams
+	dm        *dockerMachine
+}
+
+func newDockerDesktopAdmin(iostreams genericclioptions.IOStreams, dm *dockerMachine) *dockerDesktopAdmin {
+	return &dockerDesktopAdmin{
+		iostreams: iostreams,
+		dm:        dm,
+	}
+}
+
+func (a *dockerDesktopAdmin) EnsureInstalled(ctx context.Context) error { return nil }
+
+func (a *dockerDesktopAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
+	if registry != nil {
+		return fmt.Errorf("yap currently does not support connecting a registry to docker-desktop")
+	}
+
+	settings, err := a.dm.d4m.settings(ctx)
+	if err != nil {
+		return err
+	}
+
+	k8sChanged, err := a.dm.d4m.setK8sEnabled(settings, true)
+	if err != nil {
+		return err
+	}
+	if !k8sChanged {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(a.iostreams.ErrOut, "Enabling Kubernetes in Docker Desktop...\n")
+	return a.dm.writeSettingsAndWait(ctx, settings)
+}
+
+func (a *dockerDesktopAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return nil, fmt.Errorf("yap currently does not support connecting a registry to docker-desktop")
+}
+
+// Deleting the cluster resets the Kubernetes state, then turns Kubernetes
+// off so that it doesn't come back on the next Docker Desktop restart.
+func (a *dockerDesktopAdmin) Delete(ctx context.Context, config *api.Cluster) error {
+	err := a.dm.d4m.ResetCluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := a.dm.d4m.settings(ctx)
+	if err != nil {
+		return err
+	}
+
+	k8sChanged, err := a.dm.d4m.setK8sEnabled(settings, false)
+	if err != nil {
+		return err
+	}
+	if !k8sChanged {
+		return nil
+	}
+	return a.dm.d4m.writeSettings(ctx, settings)
+}
@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pseudonator/yap/pkg/api"
+	clusterid "github.com/pseudonator/yap/pkg/internal/cluster"
+)
+
+func TestDockerDesktopCreateEnablesKubernetes(t *testing.T) {
+	f := newFixture(t)
+	f.dockerClient.started = true
+	a := newDockerDesktopAdmin(f.controller.iostreams, f.dmachine)
+
+	err := a.Create(context.Background(), &api.Cluster{Name: "docker-desktop"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, f.d4m.lastSettings["k8sEnabled"])
+	assert.Equal(t, 1, f.d4m.settingsWriteCount)
+	assert.Contains(t, f.errOut.String(), "Enabling Kubernetes in Docker Desktop")
+
+	// Kubernetes is already enabled, so there's nothing to write.
+	err = a.Create(context.Background(), &api.Cluster{Name: "docker-desktop"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.d4m.settingsWriteCount)
+}
+
+func TestDockerDesktopDeleteDisablesKubernetes(t *testing.T) {
+	f := newFixture(t)
+	f.dockerClient.started = true
+	f.d4m.lastSettings = map[string]interface{}{"k8sEnabled": true}
+	a := newDockerDesktopAdmin(f.controller.iostreams, f.dmachine)
+
+	err := a.Delete(context.Background(), &api.Cluster{Name: "docker-desktop"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.d4m.resetCount)
+	assert.Equal(t, false, f.d4m.lastSettings["k8sEnabled"])
+	assert.Equal(t, 1, f.d4m.settingsWriteCount)
+}
+
+func TestDockerDesktopRemoteHost(t *testing.T) {
+	f := newFixture(t)
+	f.dockerClient.host = "tcp://192.168.99.100:2376"
+
+	_, err := f.controller.admin(context.Background(), clusterid.ProductDockerDesktop)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Remote Docker engines do not support Docker Desktop clusters")
+	}
+}
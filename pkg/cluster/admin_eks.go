@@ -0,0 +1,162 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+
+	"github.com/pseudonator/yap/pkg/api"
+	cexec "github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+// eksAdmin uses the eksctl CLI to create and delete clusters on Amazon EKS.
+//
+// eksctl names kubeconfig contexts after the IAM identity that created the
+// cluster, which we can't predict. So we tell eksctl not to write the
+// kubeconfig, and use the aws CLI to write a context with the name we want.
+type eksAdmin struct {
+	iostreams genericclioptions.IOStreams
+	runner    cexec.CmdRunner
+}
+
+func newEKSAdmin(iostreams genericclioptions.IOStreams, runner cexec.CmdRunner) *eksAdmin {
+	return &eksAdmin{
+		iostreams: iostreams,
+		runner:    runner,
+	}
+}
+
+func (a *eksAdmin) EnsureInstalled(ctx context.Context) error {
+	_, err := exec.LookPath("eksctl")
+	if err != nil {
+		return fmt.Errorf("eksctl not installed. Please install eksctl with these instructions: https://eksctl.io/installation/")
+	}
+	_, err = exec.LookPath("aws")
+	if err != nil {
+		return fmt.Errorf("aws not installed. Please install the AWS CLI with these instructions: https://aws.amazon.com/cli/")
+	}
+	return nil
+}
+
+func (a *eksAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
+	klog.V(3).Infof("Creating cluster with config:\n%+v\n---\n", desired)
+
+	if registry != nil {
+		return fmt.Errorf("yap currently does not support connecting a registry to eks")
+	}
+
+	name, region := eksClusterNameAndRegion(desired)
+	args := []string{
+		"create", "cluster",
+		"--name", name,
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	if desired.EKS != nil {
+		if desired.EKS.Version != "" {
+			args = append(args, "--version", desired.EKS.Version)
+		}
+		if desired.EKS.NodeGroupSize != 0 {
+			args = append(args, "--nodes", strconv.Itoa(desired.EKS.NodeGroupSize))
+		}
+		if desired.EKS.InstanceType != "" {
+			args = append(args, "--node-type", desired.EKS.InstanceType)
+		}
+	}
+
+	args = append(args, "--write-kubeconfig=false")
+
+	in := strings.NewReader("")
+	err := a.runner.RunIO(ctx,
+		genericclioptions.IOStreams{In: in, Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
+		"eksctl", args...)
+	if err != nil {
+		return errors.Wrap(err, "creating eks cluster")
+	}
+
+	args = []string{
+		"eks", "update-kubeconfig",
+		"--name", name,
+		"--alias", desired.Name,
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	err = a.runner.RunIO(ctx,
+		genericclioptions.IOStreams{In: strings.NewReader(""), Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
+		"aws", args...)
+	if err != nil {
+		return errors.Wrap(err, "writing eks kubeconfig")
+	}
+	return nil
+}
+
+func (a *eksAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return nil, fmt.Errorf("yap currently does not support connecting a registry to eks")
+}
+
+func (a *eksAdmin) Delete(ctx context.Context, config *api.Cluster) error {
+	name, region := eksClusterNameAndRegion(config)
+	args := []string{"delete", "cluster", "--name", name}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+	args = append(args, "--wait")
+
+	err := a.runner.RunIO(ctx, a.iostreams, "eksctl", args...)
+	if err != nil {
+		return errors.Wrap(err, "deleting eks cluster")
+	}
+	return nil
+}
+
+// Infers the EKS cluster name and region from the yap cluster.
+//
+// Clusters created by yap use the cluster name as the context name. But
+// we also want to be able to delete clusters created by other tools, which
+// use context names like:
+//
+// arn:aws:eks:us-east-1:[account-id]:cluster/[cluster-name] (aws eks update-kubeconfig)
+// [iam-identity]@[cluster-name].us-east-1.eksctl.io (eksctl)
+func eksClusterNameAndRegion(cluster *api.Cluster) (string, string) {
+	name := cluster.Name
+	region := ""
+	if cluster.EKS != nil {
+		region = cluster.EKS.Region
+	}
+
+	if strings.HasPrefix(name, "arn:aws:eks:") {
+		parts := strings.Split(name, ":")
+		if len(parts) == 6 && strings.HasPrefix(parts[5], "cluster/") {
+			if region == "" {
+				region = parts[3]
+			}
+			return strings.TrimPrefix(parts[5], "cluster/"), region
+		}
+	}
+
+	if strings.HasSuffix(name, ".eksctl.io") {
+		host := strings.TrimSuffix(name, ".eksctl.io")
+		if i := strings.LastIndex(host, "@"); i != -1 {
+			host = host[i+1:]
+		}
+		parts := strings.Split(host, ".")
+		if len(parts) == 2 {
+			if region == "" {
+				region = parts[1]
+			}
+			return parts[0], region
+		}
+	}
+
+	return name, region
+}
@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+func TestEKSCreateFlags(t *testing.T) {
+	f := newEKSFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{
+		Name: "my-cluster",
+		EKS: &api.EKSCluster{
+			Region:        "us-west-2",
+			NodeGroupSize: 3,
+			InstanceType:  "m5.large",
+			Version:       "1.27",
+		},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		[]string{
+			"eksctl", "create", "cluster",
+			"--name", "my-cluster",
+			"--region", "us-west-2",
+			"--version", "1.27",
+			"--nodes", "3",
+			"--node-type", "m5.large",
+			"--write-kubeconfig=false",
+		},
+		[]string{
+			"aws", "eks", "update-kubeconfig",
+			"--name", "my-cluster",
+			"--alias", "my-cluster",
+			"--region", "us-west-2",
+		},
+	}, f.calls)
+}
+
+func TestEKSCreateRegistryUnsupported(t *testing.T) {
+	f := newEKSFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{Name: "my-cluster"}, &api.Registry{Name: "yap-registry"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not support connecting a registry to eks")
+	}
+	assert.Empty(t, f.calls)
+}
+
+func TestEKSDelete(t *testing.T) {
+	f := newEKSFixture()
+	err := f.a.Delete(context.Background(), &api.Cluster{
+		Name: "my-cluster",
+		EKS:  &api.EKSCluster{Region: "us-west-2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"eksctl", "delete", "cluster", "--name", "my-cluster", "--region", "us-west-2", "--wait",
+	}, f.runner.LastArgs)
+}
+
+func TestEKSClusterNameAndRegion(t *testing.T) {
+	for _, tc := range []struct {
+		contextName string
+		name        string
+		region      string
+	}{
+		{"my-cluster", "my-cluster", ""},
+		{"arn:aws:eks:us-east-1:123456789012:cluster/my-cluster", "my-cluster", "us-east-1"},
+		{"nick@my-cluster.eu-west-1.eksctl.io", "my-cluster", "eu-west-1"},
+	} {
+		t.Run(tc.contextName, func(t *testing.T) {
+			name, region := eksClusterNameAndRegion(&api.Cluster{Name: tc.contextName})
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.region, region)
+		})
+	}
+}
+
+type eksFixture struct {
+	runner *exec.FakeCmdRunner
+	calls  [][]string
+	a      *eksAdmin
+}
+
+func newEKSFixture() *eksFixture {
+	f := &eksFixture{}
+	iostreams := genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}
+	f.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		f.calls = append(f.calls, argv)
+		return ""
+	})
+	f.a = newEKSAdmin(iostreams, f.runner)
+	return f
+}
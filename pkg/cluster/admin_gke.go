@@ -0,0 +1,150 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+
+	"github.com/pseudonator/yap/pkg/api"
+	cexec "github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+// gkeAdmin uses the gcloud CLI to create and delete clusters on
+// Google Kubernetes Engine.
+//
+// gcloud always names kubeconfig contexts gke_[project]_[location]_[name],
+// so FillDefaults gives GKE clusters a name in that form.
+type gkeAdmin struct {
+	iostreams genericclioptions.IOStreams
+	runner    cexec.CmdRunner
+}
+
+func newGKEAdmin(iostreams genericclioptions.IOStreams, runner cexec.CmdRunner) *gkeAdmin {
+	return &gkeAdmin{
+		iostreams: iostreams,
+		runner:    runner,
+	}
+}
+
+func (a *gkeAdmin) EnsureInstalled(ctx context.Context) error {
+	_, err := exec.LookPath("gcloud")
+	if err != nil {
+		return fmt.Errorf("gcloud not installed. Please install gcloud with these instructions: https://cloud.google.com/sdk/docs/install")
+	}
+	return nil
+}
+
+func (a *gkeAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
+	klog.V(3).Infof("Creating cluster with config:\n%+v\n---\n", desired)
+
+	if registry != nil {
+		return fmt.Errorf("yap currently does not support connecting a registry to gke")
+	}
+
+	project, location, name := gkeClusterID(desired)
+	if project == "" || location == "" {
+		return fmt.Errorf("gke clusters must specify a project and a zone or region")
+	}
+
+	args := []string{
+		"container", "clusters", "create", name,
+		"--project", project,
+		gkeLocationFlag(location), location,
+	}
+
+	if desired.GKE != nil {
+		if desired.GKE.MachineType != "" {
+			args = append(args, "--machine-type", desired.GKE.MachineType)
+		}
+		if desired.GKE.NodeCount != 0 {
+			args = append(args, "--num-nodes", strconv.Itoa(desired.GKE.NodeCount))
+		}
+		if desired.GKE.ReleaseChannel != "" {
+			args = append(args, "--release-channel", desired.GKE.ReleaseChannel)
+		}
+	}
+
+	in := strings.NewReader("")
+	err := a.runner.RunIO(ctx,
+		genericclioptions.IOStreams{In: in, Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
+		"gcloud", args...)
+	if err != nil {
+		return errors.Wrap(err, "creating gke cluster")
+	}
+
+	err = a.runner.RunIO(ctx,
+		genericclioptions.IOStreams{In: strings.NewReader(""), Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
+		"gcloud", "container", "clusters", "get-credentials", name,
+		"--project", project,
+		gkeLocationFlag(location), location)
+	if err != nil {
+		return errors.Wrap(err, "writing gke kubeconfig")
+	}
+	return nil
+}
+
+func (a *gkeAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return nil, fmt.Errorf("yap currently does not support connecting a registry to gke")
+}
+
+func (a *gkeAdmin) Delete(ctx context.Context, config *api.Cluster) error {
+	project, location, name := gkeClusterID(config)
+	if project == "" || location == "" {
+		return fmt.Errorf("cannot determine the project and location of gke cluster %s", config.Name)
+	}
+
+	err := a.runner.RunIO(ctx, a.iostreams,
+		"gcloud", "container", "clusters", "delete", name,
+		"--project", project,
+		gkeLocationFlag(location), location,
+		"--quiet")
+	if err != nil {
+		return errors.Wrap(err, "deleting gke cluster")
+	}
+	return nil
+}
+
+// The name of the kubeconfig context that gcloud creates for a cluster.
+func gkeContextName(project, location, name string) string {
+	return fmt.Sprintf("gke_%s_%s_%s", project, location, name)
+}
+
+// Infers the GKE project, location, and cluster name from the yap cluster.
+//
+// Prefers the context name, because that's all we have for clusters
+// created by other tools. Project IDs, locations, and cluster names
+// can't contain underscores, so the split is unambiguous.
+func gkeClusterID(cluster *api.Cluster) (string, string, string) {
+	parts := strings.Split(cluster.Name, "_")
+	if len(parts) == 4 && parts[0] == "gke" {
+		return parts[1], parts[2], parts[3]
+	}
+
+	project, location := "", ""
+	if cluster.GKE != nil {
+		project = cluster.GKE.Project
+		location = gkeLocation(cluster.GKE)
+	}
+	return project, location, cluster.Name
+}
+
+func gkeLocation(gke *api.GKECluster) string {
+	if gke.Zone != "" {
+		return gke.Zone
+	}
+	return gke.Region
+}
+
+// Zones look like us-central1-b. Regions look like us-central1.
+func gkeLocationFlag(location string) string {
+	if strings.Count(location, "-") >= 2 {
+		return "--zone"
+	}
+	return "--region"
+}
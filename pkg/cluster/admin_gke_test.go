@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+func TestGKECreateFlags(t *testing.T) {
+	f := newGKEFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{
+		Name: "gke_my-project_us-central1-b_dev",
+		GKE: &api.GKECluster{
+			Project:        "my-project",
+			Zone:           "us-central1-b",
+			MachineType:    "e2-standard-4",
+			NodeCount:      3,
+			ReleaseChannel: "regular",
+		},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		[]string{
+			"gcloud", "container", "clusters", "create", "dev",
+			"--project", "my-project",
+			"--zone", "us-central1-b",
+			"--machine-type", "e2-standard-4",
+			"--num-nodes", "3",
+			"--release-channel", "regular",
+		},
+		[]string{
+			"gcloud", "container", "clusters", "get-credentials", "dev",
+			"--project", "my-project",
+			"--zone", "us-central1-b",
+		},
+	}, f.calls)
+}
+
+func TestGKECreateMissingProject(t *testing.T) {
+	f := newGKEFixture()
+	err := f.a.Create(context.Background(), &api.Cluster{Name: "dev"}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "must specify a project")
+	}
+	assert.Empty(t, f.calls)
+}
+
+func TestGKEDeleteRegional(t *testing.T) {
+	f := newGKEFixture()
+	err := f.a.Delete(context.Background(), &api.Cluster{Name: "gke_my-project_us-central1_dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"gcloud", "container", "clusters", "delete", "dev",
+		"--project", "my-project",
+		"--region", "us-central1",
+		"--quiet",
+	}, f.runner.LastArgs)
+}
+
+func TestFillDefaultsGKE(t *testing.T) {
+	c := &api.Cluster{
+		Product: "gke",
+		Name:    "dev",
+		GKE:     &api.GKECluster{Project: "my-project", Region: "us-central1"},
+	}
+	FillDefaults(c)
+	assert.Equal(t, "gke_my-project_us-central1_dev", c.Name)
+
+	// Already-expanded names are left alone.
+	FillDefaults(c)
+	assert.Equal(t, "gke_my-project_us-central1_dev", c.Name)
+}
+
+type gkeFixture struct {
+	runner *exec.FakeCmdRunner
+	calls  [][]string
+	a      *gkeAdmin
+}
+
+func newGKEFixture() *gkeFixture {
+	f := &gkeFixture{}
+	iostreams := genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}
+	f.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		f.calls = append(f.calls, argv)
+		return ""
+	})
+	f.a = newGKEAdmin(iostreams, f.runner)
+	return f
+}
@@ -43,7 +43,7 @@ func (a *k3dAdmin) EnsureInstalled(ctx context.Context) error {
 	return nil
 }
 
-func (a *k3dAdmin) Create(ctx context.Context, desired *api.Cluster) error {
+func (a *k3dAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
 	klog.V(3).Infof("Creating cluster with config:\n%+v\n---\n", desired)
 
 	k3dV, err := a.version(ctx)
@@ -57,7 +57,7 @@ func (a *k3dAdmin) Create(ctx context.Context, desired *api.Cluster) error {
 
 	// We generate a cluster config on all versions
 	// because it does some useful validation.
-	k3dConfig, err := a.clusterConfig(desired)
+	k3dConfig, err := a.clusterConfig(desired, registry)
 	if err != nil {
 		return errors.Wrap(err, "creating k3d cluster")
 	}
@@ -69,6 +69,9 @@ func (a *k3dAdmin) Create(ctx context.Context, desired *api.Cluster) error {
 	if k3dV.LT(v5_3) {
 		// 5.2 and below
 		args := []string{"cluster", "create", k3dConfig.Name}
+		if registry != nil {
+			args = append(args, "--registry-use", registry.Name)
+		}
 
 		err := a.runner.RunIO(ctx,
 			genericclioptions.IOStreams{Out: a.iostreams.Out, ErrOut: a.iostreams.ErrOut},
@@ -99,6 +102,14 @@ func (a *k3dAdmin) Create(ctx context.Context, desired *api.Cluster) error {
 	return nil
 }
 
+func (a *k3dAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return &api.LocalRegistryHostingV1{
+		Host:                   fmt.Sprintf("localhost:%d", registry.Status.HostPort),
+		HostFromClusterNetwork: fmt.Sprintf("%s:%d", registry.Name, registry.Status.ContainerPort),
+		Help:                   "https://github.com/pseudonator/yap",
+	}, nil
+}
+
 func (a *k3dAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	clusterName := config.Name
 	if !strings.HasPrefix(clusterName, "k3d-") {
@@ -132,7 +143,7 @@ func (a *k3dAdmin) version(ctx context.Context) (semver.Version, error) {
 	return result, nil
 }
 
-func (a *k3dAdmin) clusterConfig(desired *api.Cluster) (*k3dv1alpha4.SimpleConfig, error) {
+func (a *k3dAdmin) clusterConfig(desired *api.Cluster, registry *api.Registry) (*k3dv1alpha4.SimpleConfig, error) {
 	var k3dConfig *k3dv1alpha4.SimpleConfig
 	if desired.K3D == nil || desired.K3D.V1Alpha4Simple == nil {
 		k3dConfig = &k3dv1alpha4.SimpleConfig{}
@@ -148,5 +159,9 @@ func (a *k3dAdmin) clusterConfig(desired *api.Cluster) (*k3dv1alpha4.SimpleConfi
 	}
 
 	k3dConfig.Name = strings.TrimPrefix(clusterName, "k3d-")
+
+	if registry != nil {
+		k3dConfig.Registries.Use = append(k3dConfig.Registries.Use, registry.Name)
+	}
 	return k3dConfig, nil
 }
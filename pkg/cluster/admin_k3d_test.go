@@ -26,7 +26,7 @@ func TestK3DStartFlagsV4(t *testing.T) {
 
 	err = f.a.Create(ctx, &api.Cluster{
 		Name: "k3d-my-cluster",
-	})
+	}, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, []string{
 		"k3d", "cluster", "create", "my-cluster",
@@ -48,7 +48,7 @@ func TestK3DStartFlagsV5(t *testing.T) {
 				Network: "bar",
 			},
 		},
-	})
+	}, nil)
 	require.NoError(t, err)
 	assert.Equal(t, []string{
 		"k3d", "cluster", "create", "my-cluster",
@@ -62,6 +62,24 @@ network: bar
 `)
 }
 
+func TestK3DStartFlagsRegistry(t *testing.T) {
+	f := newK3DFixture()
+
+	ctx := context.Background()
+	err := f.a.Create(ctx, &api.Cluster{
+		Name: "k3d-my-cluster",
+	}, &api.Registry{Name: "my-registry"})
+	require.NoError(t, err)
+	assert.Equal(t, f.runner.LastStdin, `kind: Simple
+apiVersion: k3d.io/v1alpha4
+metadata:
+    name: my-cluster
+registries:
+    use:
+        - my-registry
+`)
+}
+
 type k3dFixture struct {
 	runner  *exec.FakeCmdRunner
 	a       *k3dAdmin
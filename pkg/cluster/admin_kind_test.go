@@ -2,11 +2,23 @@ package cluster
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+
+	"github.com/pseudonator/yap/pkg/api"
 )
 
 func TestNodeImage(t *testing.T) {
@@ -38,3 +50,285 @@ func TestNodeImage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "kindest/node:v1.16.9@sha256:7175872357bc85847ec4b1aba46ed1d12fa054c83ac7a8a11f5c268957fd5765", img)
 }
+
+func TestKindClusterConfigWithRegistry(t *testing.T) {
+	iostreams := genericclioptions.IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+	a := newKindAdmin(iostreams, &fakeDockerClient{})
+
+	config := a.kindClusterConfig(&api.Cluster{Name: "kind-kind"}, &api.Registry{
+		Name: "kind-registry",
+		Status: api.RegistryStatus{
+			HostPort:      5001,
+			ContainerPort: 5000,
+		},
+	})
+	assert.Equal(t, []string{`[plugins."io.containerd.grpc.v1.cri".registry.mirrors."localhost:5001"]
+  endpoint = ["http://kind-registry:5000"]
+`}, config.ContainerdConfigPatches)
+}
+
+func TestKindCreate(t *testing.T) {
+	provider := &fakeKindProvider{version: "v0.18.0", clusters: []string{"other"}}
+	a := newKindAdmin(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}, &fakeDockerClient{})
+	a.provider = provider
+
+	err := a.Create(context.Background(), &api.Cluster{
+		Name:              "kind-my-cluster",
+		KubernetesVersion: "v1.25.3",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "my-cluster", provider.created)
+	assert.Equal(t, "kindest/node:v1.25.8@sha256:00d3f5314cc35327706776e95b2f8e504198ce59ac545d0200a89e69fce10b7f", provider.nodeImage)
+	assert.Equal(t, "kind.x-k8s.io/v1alpha4", provider.config.APIVersion)
+	assert.Equal(t, "", provider.deleted)
+}
+
+func TestKindCreateDeletesOrphan(t *testing.T) {
+	provider := &fakeKindProvider{version: "v0.18.0", clusters: []string{"my-cluster"}}
+	a := newKindAdmin(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}, &fakeDockerClient{})
+	a.provider = provider
+
+	err := a.Create(context.Background(), &api.Cluster{Name: "kind-my-cluster"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "my-cluster", provider.deleted)
+	assert.Equal(t, "my-cluster", provider.created)
+	assert.Equal(t, "", provider.nodeImage)
+}
+
+func TestKindMode(t *testing.T) {
+	t.Setenv(kindModeEnv, "bogus")
+	_, err := newKindProvider(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid YAP_KIND_MODE "bogus"`)
+	}
+}
+
+func TestKindLibraryVersion(t *testing.T) {
+	version, err := (&kindLibraryProvider{}).Version(context.Background())
+	require.NoError(t, err)
+
+	// Make sure we can pick node images for the kind library we're built with.
+	assert.True(t, newDefaultTableResolver().knowsKindVersion(context.Background(), version),
+		"missing node images for kind %s", version)
+}
+
+func TestKindLibraryCreateCanceled(t *testing.T) {
+	library := newFakeKindLibrary()
+	p := &kindLibraryProvider{library: library, errOut: io.Discard, gracePeriod: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	created := make(chan error, 1)
+	go func() {
+		created <- p.Create(ctx, "my-cluster", &v1alpha4.Cluster{}, "")
+	}()
+	<-library.started
+	cancel()
+
+	deleted := make(chan error, 1)
+	go func() {
+		deleted <- p.Delete(context.Background(), "my-cluster")
+	}()
+
+	// Nothing returns, or deletes, while kind is still creating.
+	select {
+	case <-created:
+		t.Fatal("Create returned before kind finished creating")
+	case <-deleted:
+		t.Fatal("Delete returned before kind finished creating")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, []string{"create my-cluster"}, library.getCalls())
+
+	close(library.release)
+	assert.Equal(t, context.Canceled, <-created)
+	require.NoError(t, <-deleted)
+	assert.Equal(t, []string{"create my-cluster", "created my-cluster", "delete my-cluster"}, library.getCalls())
+}
+
+func TestKindLibraryCreateGracePeriod(t *testing.T) {
+	library := newFakeKindLibrary()
+	defer close(library.release)
+	p := &kindLibraryProvider{library: library, errOut: io.Discard, gracePeriod: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := p.Create(ctx, "my-cluster", &v1alpha4.Cluster{}, "")
+	if assert.Error(t, err) {
+		assert.True(t, errors.Is(err, errStillRunning))
+		assert.Contains(t, err.Error(), "context canceled, but kind didn't finish creating the cluster within 10ms")
+	}
+}
+
+// A kind library whose Create blocks until released.
+type fakeKindLibrary struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func newFakeKindLibrary() *fakeKindLibrary {
+	return &fakeKindLibrary{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (l *fakeKindLibrary) record(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *fakeKindLibrary) getCalls() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.calls...)
+}
+
+func (l *fakeKindLibrary) List() ([]string, error) {
+	return nil, nil
+}
+
+func (l *fakeKindLibrary) Create(name string, options ...kindcluster.CreateOption) error {
+	l.record("create " + name)
+	close(l.started)
+	<-l.release
+	l.record("created " + name)
+	return nil
+}
+
+func (l *fakeKindLibrary) Delete(name, explicitKubeconfigPath string) error {
+	l.record("delete " + name)
+	return nil
+}
+
+func TestParseKindVersion(t *testing.T) {
+	version, err := parseKindVersion("kind v0.18.0 go1.20.2 darwin/arm64\n")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.18.0", version)
+
+	version, err = parseKindVersion("v0.7.0\n")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.7.0", version)
+
+	_, err = parseKindVersion("kind version unknown")
+	assert.Error(t, err)
+}
+
+type fakeKindProvider struct {
+	version   string
+	clusters  []string
+	created   string
+	config    *v1alpha4.Cluster
+	nodeImage string
+	deleted   string
+}
+
+func (p *fakeKindProvider) List(ctx context.Context) ([]string, error) {
+	return p.clusters, nil
+}
+
+func (p *fakeKindProvider) Create(ctx context.Context, name string, config *v1alpha4.Cluster, nodeImage string) error {
+	p.created = name
+	p.config = config
+	p.nodeImage = nodeImage
+	return nil
+}
+
+func (p *fakeKindProvider) Delete(ctx context.Context, name string) error {
+	p.deleted = name
+	return nil
+}
+
+func (p *fakeKindProvider) Version(ctx context.Context) (string, error) {
+	return p.version, nil
+}
+
+func TestNodeImageUnsupportedKind(t *testing.T) {
+	a := newKindAdmin(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}, &fakeDockerClient{})
+	a.resolvers[0] = fileResolver{path: filepath.Join(t.TempDir(), "kind-images.yaml")}
+
+	_, err := a.getNodeImage(context.Background(), "v0.99.0", "v1.27.3")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unsupported Kind version v0.99.0")
+	}
+
+	_, err = a.getNodeImage(context.Background(), "v0.18.0", "v1.12.0")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Kind v0.18.0 does not support Kubernetes v1.12")
+	}
+}
+
+func TestNodeImageFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kind-images.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+v0.20.0:
+  "1.27": kindest/node:v1.27.3@sha256:3966ac761ae0136263ffdb6cfd4db23ef8a83cba8a463690e98317add2c9ba72
+"*":
+  "1.26": my-registry/node:v1.26.6
+`), 0600))
+
+	a := newKindAdmin(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}, &fakeDockerClient{})
+	a.resolvers[0] = fileResolver{path: path}
+	ctx := context.Background()
+
+	img, err := a.getNodeImage(ctx, "v0.20.0", "v1.27.1")
+	require.NoError(t, err)
+	assert.Equal(t, "kindest/node:v1.27.3@sha256:3966ac761ae0136263ffdb6cfd4db23ef8a83cba8a463690e98317add2c9ba72", img)
+
+	// The user's file wins over the built-in table.
+	img, err = a.getNodeImage(ctx, "v0.18.0", "v1.26.0")
+	require.NoError(t, err)
+	assert.Equal(t, "my-registry/node:v1.26.6", img)
+
+	img, err = a.getNodeImage(ctx, "v0.18.0", "v1.25.0")
+	require.NoError(t, err)
+	assert.Equal(t, "kindest/node:v1.25.8@sha256:00d3f5314cc35327706776e95b2f8e504198ce59ac545d0200a89e69fce10b7f", img)
+}
+
+func TestNodeImageFromLocalImages(t *testing.T) {
+	dockerClient := &fakeDockerClient{images: []types.ImageSummary{
+		{RepoTags: []string{"kindest/node:v1.28.0"}},
+		{RepoTags: []string{"kindest/node:v1.27.1", "kindest/node:latest"}},
+		{RepoTags: []string{"kindest/node:v1.27.3"}},
+		{RepoTags: []string{"kindest/node:v1.27.2"}},
+	}}
+	a := newKindAdmin(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}, dockerClient)
+	a.resolvers[0] = fileResolver{path: filepath.Join(t.TempDir(), "kind-images.yaml")}
+
+	img, err := a.getNodeImage(context.Background(), "v0.20.0", "v1.27.0")
+	require.NoError(t, err)
+	assert.Equal(t, "kindest/node:v1.27.3", img)
+}
+
+func TestKindCreateWithNodeImage(t *testing.T) {
+	provider := &fakeKindProvider{version: "v0.99.0"}
+	a := newKindAdmin(genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr}, &fakeDockerClient{})
+	a.provider = provider
+
+	err := a.Create(context.Background(), &api.Cluster{
+		Name:              "kind-kind",
+		KubernetesVersion: "v1.27.3",
+		NodeImage:         "kindest/node:v1.27.3",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "kindest/node:v1.27.3", provider.nodeImage)
+}
+
+func TestDefaultKindImages(t *testing.T) {
+	table, err := parseKindImageTable(defaultKindImagesYAML)
+	require.NoError(t, err)
+	assert.Equal(t, 14, len(table))
+	for kindVersion, images := range table {
+		for minor, image := range images {
+			assert.True(t, strings.HasPrefix(image, "kindest/node:v"+minor+"."),
+				"kind %s: image %s does not match Kubernetes %s", kindVersion, image, minor)
+		}
+	}
+}
@@ -71,7 +71,7 @@ func (a *minikubeAdmin) version(ctx context.Context) (semver.Version, error) {
 	return result, nil
 }
 
-func (a *minikubeAdmin) Create(ctx context.Context, desired *api.Cluster) error {
+func (a *minikubeAdmin) Create(ctx context.Context, desired *api.Cluster, registry *api.Registry) error {
 	klog.V(3).Infof("Creating cluster with config:\n%+v\n---\n", desired)
 
 	clusterName := desired.Name
@@ -110,6 +110,9 @@ func (a *minikubeAdmin) Create(ctx context.Context, desired *api.Cluster) error
 	if desired.KubernetesVersion != "" {
 		args = append(args, "--kubernetes-version", desired.KubernetesVersion)
 	}
+	if registry != nil {
+		args = append(args, fmt.Sprintf("--insecure-registry=%s:%d", registry.Name, registry.Status.ContainerPort))
+	}
 
 	in := strings.NewReader("")
 
@@ -120,9 +123,28 @@ func (a *minikubeAdmin) Create(ctx context.Context, desired *api.Cluster) error
 		return errors.Wrap(err, "creating minikube cluster")
 	}
 
+	// With the docker driver, minikube creates a network with the same name
+	// as the profile.
+	if registry != nil && !inNetwork(registry, clusterName) {
+		_, _ = fmt.Fprintf(a.iostreams.ErrOut, "   Connecting minikube to registry %s\n", registry.Name)
+		err := a.dockerClient.NetworkConnect(ctx, clusterName, registry.Name, nil)
+		if err != nil {
+			return errors.Wrap(err, "connecting registry")
+		}
+	}
+
 	return nil
 }
 
+func (a *minikubeAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return &api.LocalRegistryHostingV1{
+		Host:                     fmt.Sprintf("localhost:%d", registry.Status.HostPort),
+		HostFromClusterNetwork:   fmt.Sprintf("%s:%d", registry.Name, registry.Status.ContainerPort),
+		HostFromContainerRuntime: fmt.Sprintf("%s:%d", registry.Name, registry.Status.ContainerPort),
+		Help:                     "https://github.com/pseudonator/yap",
+	}, nil
+}
+
 func (a *minikubeAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	err := a.runner.RunIO(ctx, a.iostreams, "minikube", "delete", "-p", config.Name)
 	if err != nil {
@@ -16,7 +16,7 @@ import (
 func TestMinikubeStartFlags(t *testing.T) {
 	f := newMinikubeFixture()
 	ctx := context.Background()
-	err := f.a.Create(ctx, &api.Cluster{Name: "minikube", Minikube: &api.MinikubeCluster{StartFlags: []string{"--foo"}}})
+	err := f.a.Create(ctx, &api.Cluster{Name: "minikube", Minikube: &api.MinikubeCluster{StartFlags: []string{"--foo"}}}, nil)
 	require.NoError(t, err)
 	assert.Equal(t, []string{
 		"minikube", "start",
@@ -28,9 +28,29 @@ func TestMinikubeStartFlags(t *testing.T) {
 	}, f.runner.LastArgs)
 }
 
+func TestMinikubeStartFlagsRegistry(t *testing.T) {
+	f := newMinikubeFixture()
+	ctx := context.Background()
+	err := f.a.Create(ctx, &api.Cluster{Name: "minikube"}, &api.Registry{
+		Name:   "my-registry",
+		Status: api.RegistryStatus{HostPort: 5001, ContainerPort: 5000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"minikube", "start",
+		"-p", "minikube",
+		"--driver=docker",
+		"--container-runtime=containerd",
+		"--extra-config=kubelet.max-pods=500",
+		"--insecure-registry=my-registry:5000",
+	}, f.runner.LastArgs)
+	assert.Equal(t, []string{"minikube"}, f.dockerClient.networks)
+}
+
 type minikubeFixture struct {
-	runner *exec.FakeCmdRunner
-	a      *minikubeAdmin
+	runner       *exec.FakeCmdRunner
+	dockerClient *fakeDockerClient
+	a            *minikubeAdmin
 }
 
 func newMinikubeFixture() *minikubeFixture {
@@ -43,7 +63,8 @@ func newMinikubeFixture() *minikubeFixture {
 		return ""
 	})
 	return &minikubeFixture{
-		runner: runner,
-		a:      newMinikubeAdmin(iostreams, dockerClient, runner),
+		runner:       runner,
+		dockerClient: dockerClient,
+		a:            newMinikubeAdmin(iostreams, dockerClient, runner),
 	}
 }
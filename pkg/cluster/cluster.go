@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"runtime"
 	"sort"
 	"strconv"
@@ -20,9 +21,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -88,8 +91,12 @@ type Controller struct {
 	configWriter                configWriter
 	clientLoader                clientLoader
 	socat                       socatController
+	registryCtl                 registryController
+	confirmRecreate             RecreateConfirmer
+	rollbackOnFailure           bool
 	waitForKubeConfigTimeout    time.Duration
 	waitForClusterCreateTimeout time.Duration
+	watchInterval               time.Duration
 	os                          string
 
 	// TODO: We should try to split this up into two structs - the part that needs
@@ -108,7 +115,7 @@ func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, erro
 		return loader.RawConfig()
 	})
 
-	configWriter := kubeconfigWriter{iostreams: iostreams}
+	configWriter := newKubeconfigWriter(iostreams)
 
 	clientLoader := clientLoader(func(restConfig *rest.Config) (kubernetes.Interface, error) {
 		return kubernetes.NewForConfig(restConfig)
@@ -168,6 +175,13 @@ func (c *Controller) getDockerClient(ctx context.Context) (dockerClient, error)
 }
 
 func (c *Controller) machine(ctx context.Context, name string, product clusterid.Product) (Machine, error) {
+	if product.IsCloudCluster() {
+		return cloudMachine{product: product}, nil
+	}
+	if product == clusterid.ProductColima {
+		return newColimaMachine(c.iostreams, c.runner, name), nil
+	}
+
 	dockerClient, err := c.getDockerClient(ctx)
 	if err != nil {
 		return nil, err
@@ -204,9 +218,14 @@ func (c *Controller) machine(ctx context.Context, name string, product clusterid
 // A cluster admin provides the basic start/stop functionality of a cluster,
 // independent of the configuration of the machine it's running on.
 func (c *Controller) admin(ctx context.Context, product clusterid.Product) (Admin, error) {
-	dockerClient, err := c.getDockerClient(ctx)
-	if err != nil {
-		return nil, err
+	// Cloud admins don't talk to Docker, so don't require a Docker client.
+	var dockerClient dockerClient
+	if !product.IsCloudCluster() {
+		var err error
+		dockerClient, err = c.getDockerClient(ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	c.mu.Lock()
@@ -218,13 +237,20 @@ func (c *Controller) admin(ctx context.Context, product clusterid.Product) (Admi
 	}
 
 	switch product {
-	// case clusterid.ProductDockerDesktop:
-	// 	if !docker.IsLocalDockerDesktop(dockerClient.DaemonHost(), c.os) {
-	// 		return nil, fmt.Errorf("Detected remote DOCKER_HOST. Remote Docker engines do not support Docker Desktop clusters: %s",
-	// 			dockerClient.DaemonHost())
-	// 	}
+	case clusterid.ProductDockerDesktop:
+		if !docker.IsLocalDockerDesktop(dockerClient.DaemonHost(), c.os) {
+			return nil, fmt.Errorf("Detected remote DOCKER_HOST. Remote Docker engines do not support Docker Desktop clusters: %s",
+				dockerClient.DaemonHost())
+		}
 
-	// 	admin = newDockerDesktopAdmin(dockerClient.DaemonHost(), c.os, c.dmachine.d4m)
+		if c.dmachine == nil {
+			machine, err := NewDockerMachine(ctx, dockerClient, c.iostreams)
+			if err != nil {
+				return nil, err
+			}
+			c.dmachine = machine
+		}
+		admin = newDockerDesktopAdmin(c.iostreams, c.dmachine)
 	case clusterid.ProductKIND:
 		admin = newKindAdmin(c.iostreams, dockerClient)
 	case clusterid.ProductK3D:
@@ -233,6 +259,12 @@ func (c *Controller) admin(ctx context.Context, product clusterid.Product) (Admi
 		admin = newMinikubeAdmin(c.iostreams, dockerClient, c.runner)
 	case clusterid.ProductColima:
 		admin = newColimaAdmin(c.iostreams, c.runner)
+	case clusterid.ProductEKS:
+		admin = newEKSAdmin(c.iostreams, c.runner)
+	case clusterid.ProductGKE:
+		admin = newGKEAdmin(c.iostreams, c.runner)
+	case clusterid.ProductAKS:
+		admin = newAKSAdmin(c.iostreams, c.runner)
 	}
 
 	if product == "" {
@@ -253,12 +285,11 @@ func (c *Controller) configCopy() *clientcmdapi.Config {
 }
 
 // Gets the port of the current API server.
-func (c *Controller) currentAPIServerPort() int {
+func (c *Controller) apiServerPort(name string) int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	current := c.config.CurrentContext
-	context, ok := c.config.Contexts[current]
+	context, ok := c.config.Contexts[name]
 	if !ok {
 		return 0
 	}
@@ -276,6 +307,29 @@ func (c *Controller) currentAPIServerPort() int {
 	return port
 }
 
+// The host:port of the cluster's API server, or empty if
+// the kubeconfig doesn't say.
+func (c *Controller) apiServerHost(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	context, ok := c.config.Contexts[name]
+	if !ok {
+		return ""
+	}
+
+	cluster, ok := c.config.Clusters[context.Cluster]
+	if !ok {
+		return ""
+	}
+
+	u, err := url.Parse(cluster.Server)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
 func (c *Controller) configCurrent() string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -306,60 +360,85 @@ func (c *Controller) client(name string) (kubernetes.Interface, error) {
 	return client, nil
 }
 
-func (c *Controller) populateCreationTimestamp(ctx context.Context, cluster *api.Cluster, client kubernetes.Interface) error {
+// Reads the nodes, for the creation timestamp and node summary.
+func (c *Controller) populateNodes(ctx context.Context, cluster *api.Cluster, client kubernetes.Interface) (api.ClusterCondition, error) {
 	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return err
+		return newCondition(api.ClusterConditionNodesReady, metav1.ConditionUnknown,
+			"NodeListFailed", err.Error()), err
 	}
 
 	minTime := metav1.Time{}
+	summaries := []api.ClusterNode{}
 	for _, node := range nodes.Items {
 		cTime := node.CreationTimestamp
 		if minTime.Time.IsZero() || cTime.Time.Before(minTime.Time) {
 			minTime = cTime
 		}
+		summaries = append(summaries, nodeSummary(node))
 	}
 
 	cluster.Status.CreationTimestamp = minTime
+	cluster.Status.Nodes = summaries
 
-	return nil
+	return nodesReadyCondition(nodes.Items), nil
 }
 
-func (c *Controller) populateMachineStatus(ctx context.Context, cluster *api.Cluster) error {
+func (c *Controller) populateMachineStatus(ctx context.Context, cluster *api.Cluster) (api.ClusterCondition, error) {
 	machine, err := c.machine(ctx, cluster.Name, clusterid.Product(cluster.Product))
 	if err != nil {
-		return err
+		return newCondition(api.ClusterConditionMachineHealthy, metav1.ConditionUnknown,
+			"MachineUnknown", err.Error()), err
 	}
 
 	cpu, err := machine.CPUs(ctx)
 	if err != nil {
-		return err
+		return newCondition(api.ClusterConditionMachineHealthy, metav1.ConditionFalse,
+			"MachineUnreachable", err.Error()), err
 	}
 	cluster.Status.CPUs = cpu
-	return nil
+	return newCondition(api.ClusterConditionMachineHealthy, metav1.ConditionTrue,
+		"MachineReachable", ""), nil
 }
 
-func (c *Controller) populateClusterSpec(ctx context.Context, cluster *api.Cluster, client kubernetes.Interface) error {
+func (c *Controller) populateClusterSpec(ctx context.Context, cluster *api.Cluster, client kubernetes.Interface) (api.ClusterCondition, error) {
 	cMap, err := client.CoreV1().ConfigMaps("kube-public").Get(ctx, clusterSpecConfigMap, metav1.GetOptions{})
 	if err != nil {
-		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
-			return nil
+		if apierrors.IsNotFound(err) {
+			return newCondition(api.ClusterConditionSpecRecorded, metav1.ConditionFalse,
+				"ConfigMapNotFound", "cluster was not created by yap"), nil
 		}
-		return err
+		condition := newCondition(api.ClusterConditionSpecRecorded, metav1.ConditionUnknown,
+			"ConfigMapUnreadable", err.Error())
+		if apierrors.IsForbidden(err) {
+			return condition, nil
+		}
+		return condition, err
 	}
 
 	spec := api.Cluster{}
 	err = yaml.Unmarshal([]byte(cMap.Data["cluster.v1alpha1"]), &spec)
 	if err != nil {
-		return err
+		return newCondition(api.ClusterConditionSpecRecorded, metav1.ConditionFalse,
+			"ConfigMapInvalid", err.Error()), err
 	}
 
+	cluster.Labels = spec.Labels
 	cluster.KubernetesVersion = spec.KubernetesVersion
+	cluster.NodeImage = spec.NodeImage
 	cluster.MinCPUs = spec.MinCPUs
 	cluster.KindV1Alpha4Cluster = spec.KindV1Alpha4Cluster
 	cluster.Minikube = spec.Minikube
 	cluster.K3D = spec.K3D
-	return nil
+	cluster.Colima = spec.Colima
+	cluster.EKS = spec.EKS
+	cluster.GKE = spec.GKE
+	cluster.AKS = spec.AKS
+	cluster.Registry = spec.Registry
+
+	condition := newCondition(api.ClusterConditionSpecRecorded, metav1.ConditionTrue, "ConfigMapFound", "")
+	condition.LastTransitionTime = cMap.CreationTimestamp
+	return condition, nil
 }
 
 // If you have dead clusters in your kubeconfig, it's common for the requests to
@@ -404,33 +483,55 @@ func (c *Controller) populateCluster(ctx context.Context, cluster *api.Cluster)
 	client, err := c.client(cluster.Name)
 	if err != nil {
 		klog.V(4).Infof("WARNING: creating cluster %s client: %v\n", name, err)
+		setCondition(&cluster.Status, newCondition(api.ClusterConditionAPIServerReachable,
+			metav1.ConditionFalse, "ClientConfigInvalid", err.Error()))
 		return
 	}
 
 	cluster.Status.Current = c.configCurrent() == cluster.Name
+	cluster.Status.Host = c.apiServerHost(cluster.Name)
 
 	v, err := c.healthCheckCluster(ctx, client)
 	if err != nil {
 		cluster.Status.Error = fmt.Sprintf("healthcheck: %s", err.Error())
+		setCondition(&cluster.Status, newCondition(api.ClusterConditionAPIServerReachable,
+			metav1.ConditionFalse, "HealthCheckFailed", err.Error()))
+
+		// The cluster may have been stopped on purpose.
+		// Ask the product, so we don't report it as broken.
+		phaseCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		err = c.populatePhase(phaseCtx, cluster)
+		if err != nil {
+			klog.V(4).Infof("WARNING: reading cluster %s phase: %v\n", name, err)
+		}
 
 		// If the cluster isn't reachable, don't try updating the rest
 		// of the fields.
 		return
 	}
 
+	cluster.Status.Phase = api.ClusterPhaseRunning
 	cluster.Status.KubernetesVersion = v.GitVersion
+	setCondition(&cluster.Status, newCondition(api.ClusterConditionAPIServerReachable,
+		metav1.ConditionTrue, "HealthCheckSucceeded", ""))
 
+	// Each goroutine fills in its own condition, and we
+	// add them to the status once they're all done.
+	var nodesReady, machineHealthy, specRecorded api.ClusterCondition
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
-		err := c.populateCreationTimestamp(ctx, cluster, client)
+		var err error
+		nodesReady, err = c.populateNodes(ctx, cluster, client)
 		if err != nil {
-			klog.V(4).Infof("WARNING: reading cluster %s creation time: %v\n", name, err)
+			klog.V(4).Infof("WARNING: reading cluster %s nodes: %v\n", name, err)
 		}
 		return err
 	})
 
 	g.Go(func() error {
-		err := c.populateMachineStatus(ctx, cluster)
+		var err error
+		machineHealthy, err = c.populateMachineStatus(ctx, cluster)
 		if err != nil {
 			klog.V(4).Infof("WARNING: reading cluster %s machine: %v\n", name, err)
 		}
@@ -438,17 +539,34 @@ func (c *Controller) populateCluster(ctx context.Context, cluster *api.Cluster)
 	})
 
 	g.Go(func() error {
-		err := c.populateClusterSpec(ctx, cluster, client)
+		var err error
+		specRecorded, err = c.populateClusterSpec(ctx, cluster, client)
 		if err != nil {
 			klog.V(4).Infof("WARNING: reading cluster %s spec: %v\n", name, err)
 		}
 		return err
 	})
 
+	g.Go(func() error {
+		err := c.populateLocalRegistryHosting(ctx, cluster, client)
+		if err != nil {
+			klog.V(4).Infof("WARNING: reading cluster %s registry: %v\n", name, err)
+		}
+		return err
+	})
+
 	err = g.Wait()
 	if err != nil {
 		cluster.Status.Error = fmt.Sprintf("reading status: %s", err.Error())
 	}
+
+	for _, condition := range []api.ClusterCondition{nodesReady, specRecorded, machineHealthy} {
+		// If another goroutine failed first, this one may have been canceled
+		// before it had anything to report.
+		if condition.Type != "" {
+			setCondition(&cluster.Status, condition)
+		}
+	}
 }
 
 func FillDefaults(cluster *api.Cluster) {
@@ -458,6 +576,20 @@ func FillDefaults(cluster *api.Cluster) {
 		cluster.Name = fmt.Sprintf("kind-%s", cluster.KindV1Alpha4Cluster.Name)
 	}
 
+	// GKE contexts are always named gke_[project]_[location]_[name],
+	// so expand short names to match.
+	if clusterid.Product(cluster.Product) == clusterid.ProductGKE && cluster.GKE != nil &&
+		!strings.HasPrefix(cluster.Name, "gke_") {
+		location := gkeLocation(cluster.GKE)
+		if cluster.GKE.Project != "" && location != "" {
+			name := cluster.Name
+			if name == "" {
+				name = clusterid.ProductGKE.DefaultClusterName()
+			}
+			cluster.Name = gkeContextName(cluster.GKE.Project, location, name)
+		}
+	}
+
 	// Create a default name if one isn't in the YAML.
 	// The default name is determined by the underlying product.
 	if cluster.Name == "" {
@@ -468,10 +600,22 @@ func FillDefaults(cluster *api.Cluster) {
 	if cluster.KindV1Alpha4Cluster != nil {
 		cluster.KindV1Alpha4Cluster.Name = strings.TrimPrefix(cluster.Name, "kind-")
 	}
+
+	// Record the MetalLB address pool that colima gets, so that
+	// changing the default later doesn't look like a config change.
+	if clusterid.Product(cluster.Product) == clusterid.ProductColima {
+		if cluster.Colima == nil {
+			cluster.Colima = &api.ColimaCluster{}
+		}
+		if cluster.Colima.MetalLbCidr == "" {
+			cluster.Colima.MetalLbCidr = defaultColimaMetalLbCidr
+		}
+	}
 }
 
 func supportsKubernetesVersion(product clusterid.Product, version string) bool {
-	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube
+	return product == clusterid.ProductKIND || product == clusterid.ProductMinikube ||
+		product == clusterid.ProductAKS
 }
 
 func (c *Controller) canReconcileK8sVersion(ctx context.Context, desired, existing *api.Cluster) bool {
@@ -496,47 +640,105 @@ func (c *Controller) canReconcileK8sVersion(ctx context.Context, desired, existi
 		return dv.Major == ev.Major && dv.Minor == ev.Minor
 	}
 
+	// AKS accepts 1.27 or 1.27.3, picks the patch itself for 1.27,
+	// and reports v1.27.3.
+	if clusterid.Product(desired.Product) == clusterid.ProductAKS {
+		return versionHasPrefix(existing.Status.KubernetesVersion, desired.KubernetesVersion)
+	}
+
 	return false
 }
 
-func (c *Controller) deleteIfIrreconcilable(ctx context.Context, desired, existing *api.Cluster) error {
+// Checks whether the version matches every component of the prefix,
+// so that 1.27 matches v1.27.3, but 1.27.2 doesn't.
+func versionHasPrefix(version, prefix string) bool {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	prefix = strings.TrimPrefix(prefix, "v")
+
+	vParts := strings.Split(version, ".")
+	pParts := strings.Split(prefix, ".")
+	if len(pParts) > len(vParts) {
+		return false
+	}
+	for i, p := range pParts {
+		if p != vParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks whether the existing cluster can be reconciled with the desired
+// cluster in place.
+//
+// If not, returns the reason the cluster needs to be recreated, and a diff
+// of the product configs if that's what changed.
+func (c *Controller) recreateReason(ctx context.Context, desired, existing *api.Cluster) (string, string) {
 	if existing.Name == "" {
-		// Nothing to delete
-		return nil
+		// Nothing to recreate
+		return "", ""
 	}
 
-	needsDelete := false
 	if existing.Product != "" && existing.Product != desired.Product {
-		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Deleting cluster %s to change admin from %s to %s\n",
-			desired.Name, existing.Product, desired.Product)
-		needsDelete = true
+		return fmt.Sprintf("desired product (%s) does not match current (%s)",
+			desired.Product, existing.Product), ""
 	} else if !c.canReconcileK8sVersion(ctx, desired, existing) {
-		_, _ = fmt.Fprintf(c.iostreams.ErrOut,
-			"Deleting cluster %s because desired Kubernetes version (%s) does not match current (%s)\n",
-			desired.Name, desired.KubernetesVersion, existing.Status.KubernetesVersion)
-		needsDelete = true
+		return fmt.Sprintf("desired Kubernetes version (%s) does not match current (%s)",
+			desired.KubernetesVersion, existing.Status.KubernetesVersion), ""
+	} else if desired.NodeImage != "" && existing.NodeImage != desired.NodeImage {
+		return fmt.Sprintf("desired node image (%s) does not match current (%s)",
+			desired.NodeImage, existing.NodeImage), ""
 	} else if desired.KindV1Alpha4Cluster != nil && !cmp.Equal(existing.KindV1Alpha4Cluster, desired.KindV1Alpha4Cluster) {
-		_, _ = fmt.Fprintf(c.iostreams.ErrOut,
-			"Deleting cluster %s because desired Kind config does not match current.\nCluster config diff: %s\n",
-			desired.Name, cmp.Diff(existing.KindV1Alpha4Cluster, desired.KindV1Alpha4Cluster))
-		needsDelete = true
+		return "desired Kind config does not match current",
+			cmp.Diff(existing.KindV1Alpha4Cluster, desired.KindV1Alpha4Cluster)
 	} else if desired.Minikube != nil && !cmp.Equal(existing.Minikube, desired.Minikube) {
-		_, _ = fmt.Fprintf(c.iostreams.ErrOut,
-			"Deleting cluster %s because desired Minikube config does not match current.\nCluster config diff: %s\n",
-			desired.Name, cmp.Diff(existing.Minikube, desired.Minikube))
-		needsDelete = true
+		return "desired Minikube config does not match current",
+			cmp.Diff(existing.Minikube, desired.Minikube)
 	} else if desired.K3D != nil && !cmp.Equal(existing.K3D, desired.K3D) {
-		_, _ = fmt.Fprintf(c.iostreams.ErrOut,
-			"Deleting cluster %s because desired K3D config does not match current.\nCluster config diff: %s\n",
-			desired.Name, cmp.Diff(existing.K3D, desired.K3D))
-		needsDelete = true
-	}
+		return "desired K3D config does not match current",
+			cmp.Diff(existing.K3D, desired.K3D)
+	} else if desired.Colima != nil && !cmp.Equal(colimaRecreateConfig(existing.Colima), colimaRecreateConfig(desired.Colima)) {
+		return "desired Colima config does not match current",
+			cmp.Diff(colimaRecreateConfig(existing.Colima), colimaRecreateConfig(desired.Colima))
+	} else if desired.EKS != nil && !cmp.Equal(existing.EKS, desired.EKS) {
+		return "desired EKS config does not match current",
+			cmp.Diff(existing.EKS, desired.EKS)
+	} else if desired.GKE != nil && !cmp.Equal(existing.GKE, desired.GKE) {
+		return "desired GKE config does not match current",
+			cmp.Diff(existing.GKE, desired.GKE)
+	} else if desired.AKS != nil && !cmp.Equal(existing.AKS, desired.AKS) {
+		return "desired AKS config does not match current",
+			cmp.Diff(existing.AKS, desired.AKS)
+	} else if existing.Registry != desired.Registry {
+		// We can't connect a registry to a running cluster,
+		// so we have to recreate it.
+		return fmt.Sprintf("desired registry (%s) does not match current (%s)",
+			desired.Registry, existing.Registry), ""
+	}
+	return "", ""
+}
 
-	if !needsDelete {
+func (c *Controller) deleteIfIrreconcilable(ctx context.Context, desired, existing *api.Cluster) error {
+	reason, diff := c.recreateReason(ctx, desired, existing)
+	if reason == "" {
 		return nil
 	}
 
-	err := c.Delete(ctx, desired.Name)
+	err := c.confirmDelete(ctx, existing, reason)
+	if err != nil {
+		return err
+	}
+
+	if diff != "" {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut,
+			"Deleting cluster %s because %s.\nCluster config diff: %s\n", desired.Name, reason, diff)
+	} else {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Deleting cluster %s because %s\n", desired.Name, reason)
+	}
+
+	err = c.Delete(ctx, desired.Name)
 	if err != nil {
 		return err
 	}
@@ -544,23 +746,57 @@ func (c *Controller) deleteIfIrreconcilable(ctx context.Context, desired, existi
 	return nil
 }
 
-// Compare the desired cluster against the existing cluster, and reconcile
-// the two to match.
-func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Cluster, error) {
+// Checks that the desired cluster config is something we know how to apply.
+func validate(desired *api.Cluster) error {
 	if desired.Product == "" {
-		return nil, fmt.Errorf("product field must be non-empty")
+		return fmt.Errorf("product field must be non-empty")
 	}
 	if desired.KubernetesVersion != "" && !supportsKubernetesVersion(clusterid.Product(desired.Product), desired.KubernetesVersion) {
-		return nil, fmt.Errorf("product %s does not support a custom Kubernetes version", desired.Product)
+		return fmt.Errorf("product %s does not support a custom Kubernetes version", desired.Product)
+	}
+	if desired.NodeImage != "" && clusterid.Product(desired.Product) != clusterid.ProductKIND {
+		return fmt.Errorf("nodeImage may only be set on clusters with product: kind. Actual product: %s", desired.Product)
 	}
 	if desired.KindV1Alpha4Cluster != nil && clusterid.Product(desired.Product) != clusterid.ProductKIND {
-		return nil, fmt.Errorf("kind config may only be set on clusters with product: kind. Actual product: %s", desired.Product)
+		return fmt.Errorf("kind config may only be set on clusters with product: kind. Actual product: %s", desired.Product)
 	}
 	if desired.Minikube != nil && clusterid.Product(desired.Product) != clusterid.ProductMinikube {
-		return nil, fmt.Errorf("minikube config may only be set on clusters with product: minikube. Actual product: %s", desired.Product)
+		return fmt.Errorf("minikube config may only be set on clusters with product: minikube. Actual product: %s", desired.Product)
 	}
 	if desired.K3D != nil && clusterid.Product(desired.Product) != clusterid.ProductK3D {
-		return nil, fmt.Errorf("k3d config may only be set on clusters with product: k3d. Actual product: %s", desired.Product)
+		return fmt.Errorf("k3d config may only be set on clusters with product: k3d. Actual product: %s", desired.Product)
+	}
+	if desired.Colima != nil && clusterid.Product(desired.Product) != clusterid.ProductColima {
+		return fmt.Errorf("colima config may only be set on clusters with product: colima. Actual product: %s", desired.Product)
+	}
+	if desired.EKS != nil && clusterid.Product(desired.Product) != clusterid.ProductEKS {
+		return fmt.Errorf("eks config may only be set on clusters with product: eks. Actual product: %s", desired.Product)
+	}
+	if desired.GKE != nil && clusterid.Product(desired.Product) != clusterid.ProductGKE {
+		return fmt.Errorf("gke config may only be set on clusters with product: gke. Actual product: %s", desired.Product)
+	}
+	if desired.AKS != nil && clusterid.Product(desired.Product) != clusterid.ProductAKS {
+		return fmt.Errorf("aks config may only be set on clusters with product: aks. Actual product: %s", desired.Product)
+	}
+	if desired.GKE != nil && desired.GKE.Zone != "" && desired.GKE.Region != "" {
+		return fmt.Errorf("gke config may only set one of zone or region")
+	}
+	if desired.Registry != "" && !supportsRegistry(clusterid.Product(desired.Product)) {
+		return fmt.Errorf("product %s does not support a registry", desired.Product)
+	}
+	err := metav1validation.ValidateLabels(desired.Labels, field.NewPath("labels")).ToAggregate()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Compare the desired cluster against the existing cluster, and reconcile
+// the two to match.
+func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (result *api.Cluster, err error) {
+	err = validate(desired)
+	if err != nil {
+		return nil, err
 	}
 
 	FillDefaults(desired)
@@ -596,14 +832,18 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	}
 
 	// If we can't reconcile the two clusters, delete it now.
-	// Check for a --force flag, and only delete the cluster
-	// if there's a --force.
-
+	// If the cluster is running workloads, the RecreateConfirmer
+	// gets a chance to stop us.
 	err = c.deleteIfIrreconcilable(ctx, desired, existingCluster)
 	if err != nil {
 		return nil, err
 	}
 
+	// Only roll back clusters that this Apply creates from scratch. A cluster
+	// that already existed may just be stopped or unreachable, and rolling back
+	// would delete it.
+	createsFromScratch := existingCluster.Name == ""
+
 	// Fetch the admin driver for this product, for setting up the cluster on top of
 	// the machine.
 	admin, err := c.admin(ctx, clusterid.Product(desired.Product))
@@ -611,9 +851,18 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 		return nil, err
 	}
 
+	var reg *api.Registry
+	if desired.Registry != "" {
+		reg, err = c.ensureRegistryExists(ctx, desired.Registry)
+		if err != nil {
+			return nil, errors.Wrap(err, "configuring registry")
+		}
+	}
+
 	existingStatus := existingCluster.Status
 	needsRestart := existingStatus.CreationTimestamp.Time.IsZero() ||
-		existingStatus.CPUs < desired.MinCPUs
+		existingStatus.CPUs < desired.MinCPUs ||
+		colimaMemoryChanged(desired, existingCluster)
 	if needsRestart {
 		err := machine.Restart(ctx, desired, existingCluster)
 		if err != nil {
@@ -625,8 +874,23 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	needsCreate := existingStatus.CreationTimestamp.Time.IsZero() ||
 		desired.Name != existingCluster.Name ||
 		desired.Product != existingCluster.Product
+	if needsCreate && createsFromScratch && c.shouldRollbackOnFailure() {
+		// Covers everything from here on, including interrupts,
+		// which show up as a canceled context. Runs after Apply returns,
+		// so after the admin's Create has stopped.
+		defer func() {
+			if err == nil {
+				return
+			}
+			rollbackErr := c.rollbackCreate(admin, desired, err)
+			if rollbackErr != nil {
+				err = fmt.Errorf("%v\nRollback failed: %v", err, rollbackErr)
+			}
+		}()
+	}
+
 	if needsCreate {
-		err := admin.Create(ctx, desired)
+		err := admin.Create(ctx, desired, reg)
 		if err != nil {
 			return nil, err
 		}
@@ -649,19 +913,21 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 	}
 
 	if needsCreate {
-		// If the cluster apiserver is in a remote docker cluster,
-		// set up a portforwarder.
-		err := c.maybeCreateForwarderForCurrentCluster(ctx, c.iostreams.ErrOut)
-		if err != nil {
-			return nil, err
-		}
+		if !clusterid.Product(desired.Product).IsCloudCluster() {
+			// If the cluster apiserver is in a remote docker cluster,
+			// set up a portforwarder.
+			err := c.maybeCreateForwarder(ctx, c.iostreams.ErrOut, desired.Name)
+			if err != nil {
+				return nil, err
+			}
 
-		err = c.maybeFixKubeConfigInsideContainer(ctx, desired)
-		if err != nil {
-			return nil, err
+			err = c.maybeFixKubeConfigInsideContainer(ctx, desired)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		err = c.waitForHealthCheckAfterCreate(ctx, desired)
+		err := c.waitForHealthCheckAfterCreate(ctx, desired)
 		if err != nil {
 			return nil, err
 		}
@@ -670,6 +936,19 @@ func (c *Controller) Apply(ctx context.Context, desired *api.Cluster) (*api.Clus
 		if err != nil {
 			return nil, errors.Wrap(err, "configuring cluster")
 		}
+	} else if needsRestart || !labels.Equals(desired.Labels, existingCluster.Labels) {
+		// The VM size and labels can change without recreating the cluster.
+		err = c.writeClusterSpec(ctx, desired)
+		if err != nil {
+			return nil, errors.Wrap(err, "updating cluster spec")
+		}
+	}
+
+	if reg != nil {
+		err = c.writeRegistryHosting(ctx, admin, desired, reg)
+		if err != nil {
+			return nil, errors.Wrap(err, "configuring cluster registry")
+		}
 	}
 
 	return c.Get(ctx, desired.Name)
@@ -755,6 +1034,15 @@ func (c *Controller) Current(ctx context.Context) (*api.Cluster, error) {
 	return c.Get(ctx, current)
 }
 
+// Switches the current kubeconfig context to the given cluster.
+func (c *Controller) UseContext(name string) error {
+	err := c.configWriter.SetContext(name)
+	if err != nil {
+		return fmt.Errorf("switching to cluster context %s: %v", name, err)
+	}
+	return c.reloadConfigs()
+}
+
 func (c *Controller) Get(ctx context.Context, name string) (*api.Cluster, error) {
 	config := c.configCopy()
 	ct, ok := config.Contexts[name]
@@ -778,13 +1066,13 @@ func (c *Controller) Get(ctx context.Context, name string) (*api.Cluster, error)
 }
 
 func (c *Controller) List(ctx context.Context, options ListOptions) (*api.ClusterList, error) {
-	selector, err := fields.ParseSelector(options.FieldSelector)
+	selector, err := newClusterSelector(options)
 	if err != nil {
 		return nil, err
 	}
 
 	config := c.configCopy()
-	names := make([]string, 0, len(c.config.Contexts))
+	names := make([]string, 0, len(config.Contexts))
 	for name, ct := range config.Contexts {
 		_, ok := config.Clusters[ct.Cluster]
 		if !ok {
@@ -801,7 +1089,7 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Cluste
 	g, ctx := errgroup.WithContext(ctx)
 
 	for i, name := range names {
-		ct := c.config.Contexts[name]
+		ct := config.Contexts[name]
 		name := name
 		i := i
 		g.Go(func() error {
@@ -810,10 +1098,13 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Cluste
 				Name:     name,
 				Product:  clusterid.ProductFromContext(ct, config.Clusters[ct.Cluster]).String(),
 			}
-			if !selector.Matches((*clusterFields)(cluster)) {
+			if !selector.mightMatch(cluster) {
 				return nil
 			}
 			c.populateCluster(ctx, cluster)
+			if !selector.matches(cluster) {
+				return nil
+			}
 			all[i] = cluster
 			return nil
 		})
@@ -840,7 +1131,9 @@ func (c *Controller) List(ctx context.Context, options ListOptions) (*api.Cluste
 
 // If the current cluster is on a remote docker instance,
 // we need a port-forwarder to connect it.
-func (c *Controller) maybeCreateForwarderForCurrentCluster(ctx context.Context, errOut io.Writer) error {
+// Other clusters may be applied concurrently, so we look up the cluster
+// by name rather than trusting the current context.
+func (c *Controller) maybeCreateForwarder(ctx context.Context, errOut io.Writer, name string) error {
 	dockerClient, err := c.getDockerClient(ctx)
 	if err != nil {
 		return err
@@ -850,7 +1143,7 @@ func (c *Controller) maybeCreateForwarderForCurrentCluster(ctx context.Context,
 		return nil
 	}
 
-	port := c.currentAPIServerPort()
+	port := c.apiServerPort(name)
 	if port == 0 {
 		return nil
 	}
@@ -887,13 +1180,16 @@ func (c *Controller) waitForContextCreate(ctx context.Context, cluster *api.Clus
 	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Waiting %s for cluster %q to create kubectl context...\n",
 		duration.ShortHumanDuration(c.waitForKubeConfigTimeout), cluster.Name)
 	var lastErr error
-	err = wait.Poll(time.Second, c.waitForKubeConfigTimeout, func() (bool, error) {
+	err = wait.PollWithContext(ctx, time.Second, c.waitForKubeConfigTimeout, func(ctx context.Context) (bool, error) {
 		err := refreshAndCheckOK()
 		lastErr = err
 		isSuccess := err == nil
 		return isSuccess, nil
 	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "waiting for kubernetes context")
+		}
 		return fmt.Errorf("kubernetes context never created: %v", lastErr)
 	}
 	return nil
@@ -936,13 +1232,16 @@ func (c *Controller) waitForHealthCheckAfterCreate(ctx context.Context, cluster
 	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Waiting %s for Kubernetes cluster %q to start...\n",
 		duration.ShortHumanDuration(c.waitForClusterCreateTimeout), cluster.Name)
 	var lastErr error
-	err = wait.Poll(time.Second, c.waitForClusterCreateTimeout, func() (bool, error) {
+	err = wait.PollWithContext(ctx, time.Second, c.waitForClusterCreateTimeout, func(ctx context.Context) (bool, error) {
 		err := checkOK()
 		lastErr = err
 		isSuccess := err == nil
 		return isSuccess, nil
 	})
 	if err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrap(ctx.Err(), "waiting for cluster to start")
+		}
 		return fmt.Errorf("timed out waiting for cluster to start: %v", lastErr)
 	}
 	return nil
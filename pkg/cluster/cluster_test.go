@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,12 +19,15 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	discoveryfake "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 
@@ -40,6 +44,19 @@ func TestClusterGet(t *testing.T) {
 	assert.Equal(t, cluster.Product, "microk8s")
 }
 
+func TestClusterGetAKS(t *testing.T) {
+	f := newFixture(t)
+	f.config.Contexts["my-aks"] = &clientcmdapi.Context{Cluster: "my-aks"}
+	f.config.Clusters["my-aks"] = &clientcmdapi.Cluster{
+		Server: "https://my-aks-dns-1a2b3c4d.hcp.eastus.azmk8s.io:443",
+	}
+	require.NoError(t, f.controller.reloadConfigs())
+
+	cluster, err := f.controller.Get(context.Background(), "my-aks")
+	require.NoError(t, err)
+	assert.Equal(t, "aks", cluster.Product)
+}
+
 func TestClusterCurrent(t *testing.T) {
 	c := newFakeController(t)
 	cluster, err := c.Current(context.Background())
@@ -108,6 +125,82 @@ func TestClusterListSelectorNoMatch(t *testing.T) {
 	assert.Equal(t, 0, len(clusters.Items))
 }
 
+func TestClusterListSelectorStatus(t *testing.T) {
+	c := newFakeController(t)
+	clusters, err := c.List(context.Background(), ListOptions{FieldSelector: "status.current=true"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+	assert.Equal(t, "microk8s", clusters.Items[0].Name)
+
+	// Unhealthy clusters.
+	clusters, err = c.List(context.Background(), ListOptions{FieldSelector: "status.error!="})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+	assert.Equal(t, "docker-desktop", clusters.Items[0].Name)
+
+	clusters, err = c.List(context.Background(), ListOptions{FieldSelector: "metadata.name!=microk8s,spec.minCPUs=0"})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+	assert.Equal(t, "docker-desktop", clusters.Items[0].Name)
+}
+
+func TestClusterListSelectorUnsupported(t *testing.T) {
+	c := newFakeController(t)
+	_, err := c.List(context.Background(), ListOptions{FieldSelector: "status.cpus=4"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field label not supported: status.cpus")
+	}
+
+	_, err = c.List(context.Background(), ListOptions{FieldSelector: "metadata.labels.team=payments"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field label not supported: metadata.labels.team. Select clusters by label with -l")
+	}
+}
+
+func TestClusterApplyLabels(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	cluster := &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Labels:  map[string]string{"team": "payments"},
+	}
+	result, err := f.controller.Apply(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments"}, result.Labels)
+
+	clusters, err := f.controller.List(context.Background(), ListOptions{
+		FieldSelector: "name=kind-kind",
+		LabelSelector: "team=payments",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters.Items))
+
+	// Changing labels updates the cluster in place.
+	kindAdmin.created = nil
+	cluster.Labels = map[string]string{"team": "billing", "ephemeral": "true"}
+	result, err = f.controller.Apply(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, cluster.Labels, result.Labels)
+
+	clusters, err = f.controller.List(context.Background(), ListOptions{LabelSelector: "team=payments"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(clusters.Items))
+}
+
+func TestClusterApplyLabelsInvalid(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Labels:  map[string]string{"team": "payments!"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "labels: Invalid value")
+	}
+}
+
 func TestClusterGetMissing(t *testing.T) {
 	c := newFakeController(t)
 	_, err := c.Get(context.Background(), "dunkees")
@@ -294,6 +387,114 @@ func TestClusterApplyKindConfig(t *testing.T) {
 	assert.Contains(t, f.errOut.String(), "desired Kind config does not match current")
 }
 
+func TestClusterApplyRecreateRefused(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	kindAdmin.created = nil
+
+	_, err = f.fakeK8s.CoreV1().Pods("default").Create(context.Background(),
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = f.fakeK8s.CoreV1().Pods("kube-system").Create(context.Background(),
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	var confirmed []string
+	f.controller.SetRecreateConfirmer(func(cluster *api.Cluster, reason string, workloads []string) (bool, error) {
+		confirmed = workloads
+		return false, nil
+	})
+
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product:           string(clusterid.ProductKIND),
+		KubernetesVersion: "v1.21.0",
+	})
+	if assert.Error(t, err) {
+		assert.True(t, IsRecreateRefused(err))
+		assert.Contains(t, err.Error(), "refusing to delete cluster kind-kind")
+	}
+	assert.Equal(t, []string{"default/web"}, confirmed)
+	assert.Nil(t, kindAdmin.deleted)
+	assert.Nil(t, kindAdmin.created)
+
+	f.controller.SetRecreateConfirmer(func(cluster *api.Cluster, reason string, workloads []string) (bool, error) {
+		return true, nil
+	})
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product:           string(clusterid.ProductKIND),
+		KubernetesVersion: "v1.21.0",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", kindAdmin.deleted.Name)
+	assert.Equal(t, "kind-kind", kindAdmin.created.Name)
+}
+
+func TestClusterApplyRecreateNoWorkloads(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+
+	called := false
+	f.controller.SetRecreateConfirmer(func(cluster *api.Cluster, reason string, workloads []string) (bool, error) {
+		called = true
+		return false, nil
+	})
+
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product:           string(clusterid.ProductKIND),
+		KubernetesVersion: "v1.21.0",
+	})
+	require.NoError(t, err)
+	assert.False(t, called)
+	assert.Equal(t, "kind-kind", kindAdmin.deleted.Name)
+}
+
+func TestClusterApplyRecreateWorkloadsUnknown(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+
+	f.fakeK8s.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", fmt.Errorf("no access"))
+	})
+
+	var confirmed []string
+	called := false
+	f.controller.SetRecreateConfirmer(func(cluster *api.Cluster, reason string, workloads []string) (bool, error) {
+		called = true
+		confirmed = workloads
+		return false, nil
+	})
+
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product:           string(clusterid.ProductKIND),
+		KubernetesVersion: "v1.21.0",
+	})
+	if assert.Error(t, err) {
+		assert.True(t, IsRecreateRefused(err))
+		assert.Contains(t, err.Error(), "refusing to delete cluster kind-kind, because its workloads couldn't be listed")
+	}
+	assert.True(t, called)
+	assert.Nil(t, confirmed)
+	assert.Nil(t, kindAdmin.deleted)
+}
+
 func TestClusterApplyMinikubeConfig(t *testing.T) {
 	f := newFixture(t)
 	f.setOS("darwin")
@@ -334,6 +535,247 @@ func TestClusterApplyMinikubeConfig(t *testing.T) {
 	assert.Contains(t, f.errOut.String(), "desired Minikube config does not match current")
 }
 
+func TestClusterApplyColimaConfig(t *testing.T) {
+	f := newFixture(t)
+	colimaAdmin := f.newFakeAdmin(clusterid.ProductColima)
+
+	cluster := &api.Cluster{
+		Product: string(clusterid.ProductColima),
+		Colima: &api.ColimaCluster{
+			ContainerRuntime: "containerd",
+		},
+	}
+	_, err := f.controller.Apply(context.Background(), cluster)
+	assert.NoError(t, err)
+	assert.Equal(t, "colima", colimaAdmin.created.Name)
+	colimaAdmin.created = nil
+
+	// Assert that re-applying the same config doesn't create a new cluster.
+	_, err = f.controller.Apply(context.Background(), cluster)
+	assert.NoError(t, err)
+	assert.Nil(t, colimaAdmin.created)
+	assert.Nil(t, colimaAdmin.deleted)
+
+	// Assert that applying a different config deletes and re-creates.
+	cluster2 := &api.Cluster{
+		Product: string(clusterid.ProductColima),
+		Colima: &api.ColimaCluster{
+			ContainerRuntime: "containerd",
+			MetalLbCidr:      "192.168.106.200/29",
+		},
+	}
+
+	f.errOut.Truncate(0)
+	_, err = f.controller.Apply(context.Background(), cluster2)
+	assert.NoError(t, err)
+	assert.Equal(t, "colima", colimaAdmin.created.Name)
+	assert.Equal(t, "colima", colimaAdmin.deleted.Name)
+	assert.Contains(t, f.errOut.String(), "desired Colima config does not match current")
+}
+
+func TestClusterApplyColimaMemory(t *testing.T) {
+	f := newFixture(t)
+	colimaAdmin := f.newFakeAdmin(clusterid.ProductColima)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductColima),
+		Colima:  &api.ColimaCluster{Memory: 4},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, defaultColimaMetalLbCidr, colimaAdmin.created.Colima.MetalLbCidr)
+	colimaAdmin.created = nil
+
+	// Assert that changing the memory restarts the VM instead of
+	// recreating the cluster.
+	f.errOut.Truncate(0)
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductColima),
+		Colima:  &api.ColimaCluster{Memory: 8},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, colimaAdmin.created)
+	assert.Nil(t, colimaAdmin.deleted)
+	assert.Contains(t, f.errOut.String(), "Restarting colima profile \"colima\" to change VM size")
+
+	// Assert that the recorded spec, with the default address pool,
+	// matches the config on the next apply.
+	plan, err := f.controller.Plan(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductColima),
+		Colima:  &api.ColimaCluster{Memory: 8},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PlanActionNoOp, plan.Action)
+}
+
+func TestClusterApplyColimaConfigWrongProduct(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Colima:  &api.ColimaCluster{ContainerRuntime: "docker"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "colima config may only be set on clusters with product: colima")
+	}
+}
+
+func TestClusterApplyKINDWithRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductKIND),
+		Registry: "kind-registry",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-registry"}, f.registryCtl.applied)
+	assert.Equal(t, "kind-registry", kindAdmin.createdRegistry.Name)
+	assert.Equal(t, "kind-registry", result.Registry)
+	assert.Equal(t, &api.LocalRegistryHostingV1{
+		Host: "localhost:5001",
+		Help: "https://github.com/pseudonator/yap",
+	}, result.Status.LocalRegistryHosting)
+
+	// Re-applying doesn't re-create the registry or the cluster.
+	kindAdmin.created = nil
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductKIND),
+		Registry: "kind-registry",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-registry"}, f.registryCtl.applied)
+	assert.Nil(t, kindAdmin.created)
+
+	// Changing the registry re-creates the cluster.
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductKIND),
+		Registry: "other-registry",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "other-registry", kindAdmin.createdRegistry.Name)
+	assert.Contains(t, f.errOut.String(),
+		"Deleting cluster kind-kind because desired registry (other-registry) does not match current (kind-registry)")
+}
+
+func TestClusterApplyRegistryUnsupported(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductColima),
+		Registry: "my-registry",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "product colima does not support a registry")
+	}
+}
+
+func TestClusterApplyEKS(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+
+	eksAdmin := f.newFakeAdmin(clusterid.ProductEKS)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductEKS),
+		Name:    "my-cluster",
+		EKS:     &api.EKSCluster{Region: "us-west-2"},
+	})
+	require.NoError(t, err)
+
+	// EKS clusters don't run on the local Docker machine, so we shouldn't
+	// try to start Docker Desktop.
+	assert.Equal(t, false, f.d4m.started)
+	assert.Equal(t, "my-cluster", eksAdmin.created.Name)
+	assert.Equal(t, "us-west-2", eksAdmin.created.EKS.Region)
+	assert.Equal(t, "my-cluster", result.Name)
+}
+
+func TestClusterApplyGKE(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+
+	gkeAdmin := f.newFakeAdmin(clusterid.ProductGKE)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductGKE),
+		Name:    "dev",
+		GKE:     &api.GKECluster{Project: "my-project", Zone: "us-central1-b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, false, f.d4m.started)
+	assert.Equal(t, "gke_my-project_us-central1-b_dev", gkeAdmin.created.Name)
+	assert.Equal(t, "gke_my-project_us-central1-b_dev", result.Name)
+}
+
+func TestClusterApplyColima(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+
+	colimaAdmin := f.newFakeAdmin(clusterid.ProductColima)
+
+	result, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductColima),
+	})
+	require.NoError(t, err)
+
+	// Colima runs its own VM, so we shouldn't try to start Docker Desktop.
+	assert.Equal(t, false, f.d4m.started)
+	assert.Equal(t, "colima", colimaAdmin.created.Name)
+	assert.Equal(t, "colima", result.Name)
+}
+
+func TestClusterApplyEKSConfigWrongProduct(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		EKS:     &api.EKSCluster{Region: "us-west-2"},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "eks config may only be set on clusters with product: eks")
+	}
+}
+
+func TestClusterApplyNodeImageWrongProduct(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:   string(clusterid.ProductMinikube),
+		NodeImage: "kindest/node:v1.27.3",
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "nodeImage may only be set on clusters with product: kind")
+	}
+}
+
+func TestClusterApplyNodeImageChange(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	cluster := &api.Cluster{
+		Product:   string(clusterid.ProductKIND),
+		NodeImage: "kindest/node:v1.27.3",
+	}
+	_, err := f.controller.Apply(context.Background(), cluster)
+	require.NoError(t, err)
+	kindAdmin.created = nil
+
+	_, err = f.controller.Apply(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Nil(t, kindAdmin.created)
+
+	f.errOut.Truncate(0)
+	cluster.NodeImage = "kindest/node:v1.27.4"
+	_, err = f.controller.Apply(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Equal(t, "kind-kind", kindAdmin.deleted.Name)
+	assert.Contains(t, f.errOut.String(),
+		"desired node image (kindest/node:v1.27.4) does not match current (kindest/node:v1.27.3)")
+}
+
 func TestClusterFixKubeConfigInContainer(t *testing.T) {
 	f := newFixture(t)
 	ctx := context.Background()
@@ -369,6 +811,7 @@ type fixture struct {
 	t            *testing.T
 	errOut       *bytes.Buffer
 	controller   *Controller
+	registryCtl  *fakeRegistryController
 	dockerClient *fakeDockerClient
 	dmachine     *dockerMachine
 	d4m          *fakeD4MClient
@@ -431,8 +874,11 @@ func newFixture(t *testing.T) *fixture {
 		return fakeK8s, nil
 	})
 
+	registryCtl := &fakeRegistryController{}
+
 	controller := &Controller{
 		iostreams:                   iostreams,
+		registryCtl:                 registryCtl,
 		runner:                      exec.NewFakeCmdRunner(func(argv []string) string { return "" }),
 		admins:                      make(map[clusterid.Product]Admin),
 		config:                      *config,
@@ -450,6 +896,7 @@ func newFixture(t *testing.T) *fixture {
 		t:            t,
 		errOut:       iostreams.ErrOut.(*bytes.Buffer),
 		controller:   controller,
+		registryCtl:  registryCtl,
 		dmachine:     dmachine,
 		d4m:          d4m,
 		dockerClient: dockerClient,
@@ -489,6 +936,12 @@ type fakeDockerClient struct {
 	host        string
 	networks    []string
 	containerID string
+	images      []types.ImageSummary
+
+	// Containers returned by ContainerList, and the
+	// lifecycle actions we've taken on them.
+	containers []types.Container
+	actions    []string
 }
 
 func (c *fakeDockerClient) DaemonHost() string {
@@ -524,16 +977,49 @@ func (d *fakeDockerClient) ImagePull(ctx context.Context, image string, options
 }
 
 func (d *fakeDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
-	return nil, nil
+	result := []types.Container{}
+	for _, c := range d.containers {
+		matches := true
+		for _, label := range options.Filters.Get("label") {
+			key, value, _ := strings.Cut(label, "=")
+			if c.Labels[key] != value {
+				matches = false
+			}
+		}
+		if matches {
+			result = append(result, c)
+		}
+	}
+	return result, nil
 }
 
 func (d *fakeDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 	return container.ContainerCreateCreatedBody{}, nil
 }
 func (d *fakeDockerClient) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	d.actions = append(d.actions, "start "+containerID)
+	return nil
+}
+
+func (d *fakeDockerClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	d.actions = append(d.actions, "stop "+containerID)
+	return nil
+}
+
+func (d *fakeDockerClient) ContainerPause(ctx context.Context, containerID string) error {
+	d.actions = append(d.actions, "pause "+containerID)
+	return nil
+}
+
+func (d *fakeDockerClient) ContainerUnpause(ctx context.Context, containerID string) error {
+	d.actions = append(d.actions, "unpause "+containerID)
 	return nil
 }
 
+func (d *fakeDockerClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	return d.images, nil
+}
+
 func (d *fakeDockerClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
 	d.networks = append(d.networks, networkID)
 	return nil
@@ -567,7 +1053,9 @@ type fakeD4MClient struct {
 
 func (c *fakeD4MClient) writeSettings(ctx context.Context, settings map[string]interface{}) error {
 	c.lastSettings = settings
-	c.docker.ncpu = settings["cpu"].(int)
+	if cpu, ok := settings["cpu"].(int); ok {
+		c.docker.ncpu = cpu
+	}
 	c.settingsWriteCount++
 	return nil
 }
@@ -581,10 +1069,13 @@ func (c *fakeD4MClient) settings(ctx context.Context) (map[string]interface{}, e
 
 func (c *fakeD4MClient) setK8sEnabled(settings map[string]interface{}, desired bool) (bool, error) {
 	enabled, ok := settings["k8sEnabled"]
-	if ok && enabled.(bool) == true {
+	if ok && enabled.(bool) == desired {
 		return false, nil
 	}
-	settings["k8sEnabled"] = true
+	if !ok && !desired {
+		return false, nil
+	}
+	settings["k8sEnabled"] = desired
 	return true, nil
 }
 
@@ -613,10 +1104,11 @@ func (c *fakeD4MClient) Open(ctx context.Context) error {
 }
 
 type fakeAdmin struct {
-	created *api.Cluster
-	deleted *api.Cluster
-	config  *clientcmdapi.Config
-	fakeK8s *fake.Clientset
+	created         *api.Cluster
+	createdRegistry *api.Registry
+	deleted         *api.Cluster
+	config          *clientcmdapi.Config
+	fakeK8s         *fake.Clientset
 }
 
 func newFakeAdmin(config *clientcmdapi.Config, fakeK8s *fake.Clientset) *fakeAdmin {
@@ -625,8 +1117,9 @@ func newFakeAdmin(config *clientcmdapi.Config, fakeK8s *fake.Clientset) *fakeAdm
 
 func (a *fakeAdmin) EnsureInstalled(ctx context.Context) error { return nil }
 
-func (a *fakeAdmin) Create(ctx context.Context, config *api.Cluster) error {
+func (a *fakeAdmin) Create(ctx context.Context, config *api.Cluster, registry *api.Registry) error {
 	a.created = config.DeepCopy()
+	a.createdRegistry = registry.DeepCopy()
 	a.config.Contexts[config.Name] = &clientcmdapi.Context{Cluster: config.Name}
 	a.config.Clusters[config.Name] = &clientcmdapi.Cluster{Server: fmt.Sprintf("http://%s.localhost/", config.Name)}
 
@@ -640,12 +1133,47 @@ func (a *fakeAdmin) Create(ctx context.Context, config *api.Cluster) error {
 	return nil
 }
 
+func (a *fakeAdmin) LocalRegistryHosting(ctx context.Context, desired *api.Cluster, registry *api.Registry) (*api.LocalRegistryHostingV1, error) {
+	return &api.LocalRegistryHostingV1{
+		Host: fmt.Sprintf("localhost:%d", registry.Status.HostPort),
+		Help: "https://github.com/pseudonator/yap",
+	}, nil
+}
+
 func (a *fakeAdmin) Delete(ctx context.Context, config *api.Cluster) error {
 	a.deleted = config.DeepCopy()
 	delete(a.config.Contexts, config.Name)
 	return nil
 }
 
+type fakeRegistryController struct {
+	registries map[string]*api.Registry
+	applied    []string
+}
+
+func (c *fakeRegistryController) Apply(ctx context.Context, r *api.Registry) (*api.Registry, error) {
+	c.applied = append(c.applied, r.Name)
+	if c.registries == nil {
+		c.registries = make(map[string]*api.Registry)
+	}
+	result := r.DeepCopy()
+	result.Status = api.RegistryStatus{
+		HostPort:      5001,
+		ContainerPort: 5000,
+		State:         "running",
+	}
+	c.registries[r.Name] = result
+	return result.DeepCopy(), nil
+}
+
+func (c *fakeRegistryController) Get(ctx context.Context, name string) (*api.Registry, error) {
+	r, ok := c.registries[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "yap.pseudonator.io", Resource: "registries"}, name)
+	}
+	return r.DeepCopy(), nil
+}
+
 type fakeConfigWriter struct {
 	config *clientcmdapi.Config
 	opts   map[string]string
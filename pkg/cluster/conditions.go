@@ -0,0 +1,167 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// The order we report conditions in.
+var conditionOrder = []api.ClusterConditionType{
+	api.ClusterConditionAPIServerReachable,
+	api.ClusterConditionNodesReady,
+	api.ClusterConditionSpecRecorded,
+	api.ClusterConditionMachineHealthy,
+}
+
+// GetCondition returns the condition of the given type, or nil if the
+// status doesn't have one.
+func GetCondition(status api.ClusterStatus, t api.ClusterConditionType) *api.ClusterCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == t {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Adds or replaces the condition of the same type, keeping
+// conditions in a stable order.
+func setCondition(status *api.ClusterStatus, condition api.ClusterCondition) {
+	existing := GetCondition(*status, condition.Type)
+	if existing != nil {
+		*existing = condition
+		return
+	}
+
+	status.Conditions = append(status.Conditions, condition)
+	rank := func(t api.ClusterConditionType) int {
+		for i, ct := range conditionOrder {
+			if ct == t {
+				return i
+			}
+		}
+		return len(conditionOrder)
+	}
+	sort.SliceStable(status.Conditions, func(i, j int) bool {
+		return rank(status.Conditions[i].Type) < rank(status.Conditions[j].Type)
+	})
+}
+
+// We usually only know what the cluster looks like right now,
+// so the transition time defaults to when we observed it.
+func newCondition(t api.ClusterConditionType, status metav1.ConditionStatus, reason, message string) api.ClusterCondition {
+	return api.ClusterCondition{
+		Type:               t,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// When we see the same condition twice, keep the original transition time.
+func preserveTransitionTimes(old, status *api.ClusterStatus) {
+	for i, c := range status.Conditions {
+		prev := GetCondition(*old, c.Type)
+		if prev != nil && prev.Status == c.Status && !prev.LastTransitionTime.IsZero() {
+			status.Conditions[i].LastTransitionTime = prev.LastTransitionTime
+		}
+	}
+}
+
+// Whether any condition changed status between two observations.
+func conditionsChanged(old, status api.ClusterStatus) bool {
+	if len(old.Conditions) != len(status.Conditions) {
+		return true
+	}
+	for _, c := range status.Conditions {
+		prev := GetCondition(old, c.Type)
+		if prev == nil || prev.Status != c.Status {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeSummary(node v1.Node) api.ClusterNode {
+	roles := []string{}
+	for label := range node.Labels {
+		role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/")
+		if ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if role := node.Labels["kubernetes.io/role"]; role != "" && !contains(roles, role) {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	ready := nodeReadyCondition(node)
+	return api.ClusterNode{
+		Name:             node.Name,
+		Role:             strings.Join(roles, ","),
+		Ready:            ready != nil && ready.Status == v1.ConditionTrue,
+		KubeletVersion:   node.Status.NodeInfo.KubeletVersion,
+		ContainerRuntime: node.Status.NodeInfo.ContainerRuntimeVersion,
+	}
+}
+
+func nodeReadyCondition(node v1.Node) *v1.NodeCondition {
+	for i, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// Summarizes the Ready conditions of all the nodes.
+//
+// The transition time is the last time any node changed readiness.
+func nodesReadyCondition(nodes []v1.Node) api.ClusterCondition {
+	if len(nodes) == 0 {
+		return newCondition(api.ClusterConditionNodesReady, metav1.ConditionFalse,
+			"NoNodes", "cluster has no nodes")
+	}
+
+	notReady := []string{}
+	lastTransition := metav1.Time{}
+	for _, node := range nodes {
+		ready := nodeReadyCondition(node)
+		if ready == nil || ready.Status != v1.ConditionTrue {
+			notReady = append(notReady, node.Name)
+		}
+		if ready != nil && lastTransition.Before(&ready.LastTransitionTime) {
+			lastTransition = ready.LastTransitionTime
+		}
+	}
+
+	var condition api.ClusterCondition
+	if len(notReady) == 0 {
+		condition = newCondition(api.ClusterConditionNodesReady, metav1.ConditionTrue,
+			"AllNodesReady", fmt.Sprintf("%d/%d nodes ready", len(nodes), len(nodes)))
+	} else {
+		condition = newCondition(api.ClusterConditionNodesReady, metav1.ConditionFalse,
+			"NodesNotReady", fmt.Sprintf("%d/%d nodes ready. Not ready: %s",
+				len(nodes)-len(notReady), len(nodes), strings.Join(notReady, ", ")))
+	}
+	if !lastTransition.IsZero() {
+		condition.LastTransitionTime = lastTransition
+	}
+	return condition
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
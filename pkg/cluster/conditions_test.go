@@ -0,0 +1,126 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+func TestClusterGetConditionsAndNodes(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.fakeK8s.CoreV1().Nodes().Create(context.Background(), &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-2",
+			Labels: map[string]string{
+				"node-role.kubernetes.io/control-plane": "",
+				"node-role.kubernetes.io/master":        "",
+			},
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}},
+			NodeInfo: v1.NodeSystemInfo{
+				KubeletVersion:          "v1.27.3",
+				ContainerRuntimeVersion: "containerd://1.7.1",
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	cluster, err := f.controller.Get(context.Background(), "microk8s")
+	require.NoError(t, err)
+
+	assert.Equal(t, []api.ClusterNode{
+		{Name: "node-1"},
+		{
+			Name:             "node-2",
+			Role:             "control-plane,master",
+			Ready:            true,
+			KubeletVersion:   "v1.27.3",
+			ContainerRuntime: "containerd://1.7.1",
+		},
+	}, cluster.Status.Nodes)
+
+	assert.Equal(t, []string{
+		"APIServerReachable=True (HealthCheckSucceeded)",
+		"NodesReady=False (NodesNotReady): 1/2 nodes ready. Not ready: node-1",
+		"SpecRecorded=False (ConfigMapNotFound): cluster was not created by yap",
+		"MachineHealthy=True (MachineReachable)",
+	}, conditionSummaries(cluster.Status))
+}
+
+func TestClusterGetMachineUnhealthy(t *testing.T) {
+	c := newFakeController(t)
+	cluster, err := c.Get(context.Background(), "docker-desktop")
+	require.NoError(t, err)
+
+	condition := GetCondition(cluster.Status, api.ClusterConditionMachineHealthy)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "MachineUnreachable", condition.Reason)
+	assert.Equal(t, "not started", condition.Message)
+}
+
+func TestNodesReadyCondition(t *testing.T) {
+	earlier := metav1.NewTime(time.Unix(1500000000, 0))
+	later := metav1.NewTime(time.Unix(1600000000, 0))
+	nodes := []v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: later},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionTrue, LastTransitionTime: earlier},
+			}},
+		},
+	}
+
+	condition := nodesReadyCondition(nodes)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "2/2 nodes ready", condition.Message)
+	assert.Equal(t, later, condition.LastTransitionTime)
+
+	condition = nodesReadyCondition(nil)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "NoNodes", condition.Reason)
+}
+
+func TestPreserveTransitionTimes(t *testing.T) {
+	earlier := metav1.NewTime(time.Unix(1500000000, 0))
+	old := api.ClusterStatus{Conditions: []api.ClusterCondition{
+		{Type: api.ClusterConditionAPIServerReachable, Status: metav1.ConditionTrue, LastTransitionTime: earlier},
+		{Type: api.ClusterConditionNodesReady, Status: metav1.ConditionFalse, LastTransitionTime: earlier},
+	}}
+
+	status := api.ClusterStatus{}
+	setCondition(&status, newCondition(api.ClusterConditionNodesReady, metav1.ConditionTrue, "AllNodesReady", ""))
+	setCondition(&status, newCondition(api.ClusterConditionAPIServerReachable, metav1.ConditionTrue, "HealthCheckSucceeded", ""))
+	assert.True(t, conditionsChanged(old, status))
+
+	preserveTransitionTimes(&old, &status)
+	assert.Equal(t, api.ClusterConditionAPIServerReachable, status.Conditions[0].Type)
+	assert.Equal(t, earlier, status.Conditions[0].LastTransitionTime)
+	assert.NotEqual(t, earlier, status.Conditions[1].LastTransitionTime)
+}
+
+func conditionSummaries(status api.ClusterStatus) []string {
+	result := []string{}
+	for _, c := range status.Conditions {
+		s := string(c.Type) + "=" + string(c.Status) + " (" + c.Reason + ")"
+		if c.Message != "" {
+			s += ": " + c.Message
+		}
+		result = append(result, s)
+	}
+	return result
+}
@@ -1,38 +1,406 @@
 package cluster
 
 import (
-	"os/exec"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
+// How long to wait for another process (like kubectl) to finish
+// writing the kubeconfig.
+const kubeconfigLockTimeout = 10 * time.Second
+
+// The poll interval while waiting on a kubeconfig lock.
+const kubeconfigLockInterval = 50 * time.Millisecond
+
+// Serializes kubeconfig writes from this process.
+var kubeconfigMu sync.Mutex
+
 type configWriter interface {
 	SetContext(name string) error
 	DeleteContext(name string) error
 	SetConfig(name, value string) error
 }
 
+// kubeconfigWriter edits the kubeconfig in-process with clientcmd.
+//
+// Honors KUBECONFIG, including lists of files. Each entry is written back to
+// the file it came from, and new entries go to the same file kubectl would
+// pick.
+//
+// Takes the same lock files as kubectl, so concurrent writers wait their turn
+// instead of clobbering each other's changes.
 type kubeconfigWriter struct {
-	iostreams genericclioptions.IOStreams
+	iostreams   genericclioptions.IOStreams
+	pathOptions *clientcmd.PathOptions
+	lockTimeout time.Duration
+}
+
+func newKubeconfigWriter(iostreams genericclioptions.IOStreams) kubeconfigWriter {
+	return kubeconfigWriter{
+		iostreams:   iostreams,
+		pathOptions: clientcmd.NewDefaultPathOptions(),
+		lockTimeout: kubeconfigLockTimeout,
+	}
 }
 
 func (w kubeconfigWriter) SetContext(name string) error {
-	cmd := exec.Command("kubectl", "config", "use-context", name)
-	cmd.Stdout = w.iostreams.Out
-	cmd.Stderr = w.iostreams.ErrOut
-	return cmd.Run()
+	return w.modify(func(config *clientcmdapi.Config) error {
+		if _, ok := config.Contexts[name]; !ok {
+			return fmt.Errorf("no context exists with the name: %q", name)
+		}
+		config.CurrentContext = name
+		return nil
+	})
 }
 
+// Deletes the context, and the cluster and user it points to, unless
+// another context still uses them.
 func (w kubeconfigWriter) DeleteContext(name string) error {
-	cmd := exec.Command("kubectl", "config", "delete-context", name)
-	cmd.Stdout = w.iostreams.Out
-	cmd.Stderr = w.iostreams.ErrOut
-	return cmd.Run()
+	return w.modify(func(config *clientcmdapi.Config) error {
+		context, ok := config.Contexts[name]
+		if !ok {
+			return fmt.Errorf("cannot delete context %s, not in kubeconfig", name)
+		}
+		delete(config.Contexts, name)
+
+		if config.CurrentContext == name {
+			config.CurrentContext = ""
+		}
+
+		clusterInUse, userInUse := false, false
+		for _, other := range config.Contexts {
+			if other.Cluster == context.Cluster {
+				clusterInUse = true
+			}
+			if other.AuthInfo == context.AuthInfo {
+				userInUse = true
+			}
+		}
+		if !clusterInUse {
+			delete(config.Clusters, context.Cluster)
+		}
+		if !userInUse {
+			delete(config.AuthInfos, context.AuthInfo)
+		}
+		return nil
+	})
 }
 
+// Sets a single kubeconfig property, using the same property names
+// as `kubectl config set` (e.g., clusters.kind-kind.server).
 func (w kubeconfigWriter) SetConfig(name, value string) error {
-	cmd := exec.Command("kubectl", "config", "set", name, value)
-	cmd.Stdout = w.iostreams.Out
-	cmd.Stderr = w.iostreams.ErrOut
-	return cmd.Run()
+	return w.modify(func(config *clientcmdapi.Config) error {
+		return setConfigProperty(config, name, value)
+	})
+}
+
+// Loads the kubeconfig under lock, applies the edit, and writes back
+// whatever changed.
+func (w kubeconfigWriter) modify(edit func(config *clientcmdapi.Config) error) error {
+	kubeconfigMu.Lock()
+	defer kubeconfigMu.Unlock()
+
+	unlock, err := w.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, err := w.pathOptions.GetStartingConfig()
+	if err != nil {
+		return fmt.Errorf("reading kubeconfig: %v", err)
+	}
+	starting := config.DeepCopy()
+
+	err = edit(config)
+	if err != nil {
+		return err
+	}
+
+	err = w.write(starting, config)
+	if err != nil {
+		return fmt.Errorf("writing kubeconfig: %v", err)
+	}
+	return nil
+}
+
+// Writes each entry that the edit changed back to the file it came from,
+// the same way clientcmd.ModifyConfig does. We can't use ModifyConfig
+// itself, because it takes the lock files that we already hold, and the
+// only way to stop it is a global that other goroutines read.
+func (w kubeconfigWriter) write(starting, edited *clientcmdapi.Config) error {
+	files := newKubeconfigFiles(w.pathOptions.GetDefaultFilename())
+
+	if starting.CurrentContext != edited.CurrentContext {
+		config, err := files.get(w.currentContextFile(edited.CurrentContext))
+		if err != nil {
+			return err
+		}
+		config.CurrentContext = edited.CurrentContext
+	}
+
+	for name, cluster := range edited.Clusters {
+		if old, ok := starting.Clusters[name]; !ok || !reflect.DeepEqual(old, cluster) {
+			config, err := files.get(cluster.LocationOfOrigin)
+			if err != nil {
+				return err
+			}
+			config.Clusters[name] = cluster
+		}
+	}
+	for name, cluster := range starting.Clusters {
+		if _, ok := edited.Clusters[name]; !ok {
+			config, err := files.get(cluster.LocationOfOrigin)
+			if err != nil {
+				return err
+			}
+			delete(config.Clusters, name)
+		}
+	}
+
+	for name, context := range edited.Contexts {
+		if old, ok := starting.Contexts[name]; !ok || !reflect.DeepEqual(old, context) {
+			config, err := files.get(context.LocationOfOrigin)
+			if err != nil {
+				return err
+			}
+			config.Contexts[name] = context
+		}
+	}
+	for name, context := range starting.Contexts {
+		if _, ok := edited.Contexts[name]; !ok {
+			config, err := files.get(context.LocationOfOrigin)
+			if err != nil {
+				return err
+			}
+			delete(config.Contexts, name)
+		}
+	}
+
+	for name, user := range edited.AuthInfos {
+		if old, ok := starting.AuthInfos[name]; !ok || !reflect.DeepEqual(old, user) {
+			config, err := files.get(user.LocationOfOrigin)
+			if err != nil {
+				return err
+			}
+			config.AuthInfos[name] = user
+		}
+	}
+	for name, user := range starting.AuthInfos {
+		if _, ok := edited.AuthInfos[name]; !ok {
+			config, err := files.get(user.LocationOfOrigin)
+			if err != nil {
+				return err
+			}
+			delete(config.AuthInfos, name)
+		}
+	}
+
+	return files.write()
+}
+
+// Picks the file for a new current context, like kubectl: the explicit
+// file if there is one, the default file when setting a context, and
+// the first file that sets one when clearing it.
+func (w kubeconfigWriter) currentContextFile(current string) string {
+	if current != "" || w.pathOptions.IsExplicitFile() {
+		return w.pathOptions.GetDefaultFilename()
+	}
+	for _, f := range w.pathOptions.GetLoadingPrecedence() {
+		config, err := clientcmd.LoadFromFile(f)
+		if err == nil && config.CurrentContext != "" {
+			return f
+		}
+	}
+	return w.pathOptions.GetDefaultFilename()
+}
+
+// The kubeconfig files that an edit touches, loaded once each.
+type kubeconfigFiles struct {
+	defaultFile string
+	configs     map[string]*clientcmdapi.Config
+	order       []string
+}
+
+func newKubeconfigFiles(defaultFile string) *kubeconfigFiles {
+	return &kubeconfigFiles{
+		defaultFile: defaultFile,
+		configs:     make(map[string]*clientcmdapi.Config),
+	}
+}
+
+// Loads a file for editing. New entries have no origin, and go to
+// the default file. A missing file loads as an empty config.
+func (f *kubeconfigFiles) get(filename string) (*clientcmdapi.Config, error) {
+	if filename == "" {
+		filename = f.defaultFile
+	}
+	if config, ok := f.configs[filename]; ok {
+		return config, nil
+	}
+
+	config, err := clientcmd.LoadFromFile(filename)
+	if os.IsNotExist(err) {
+		config, err = clientcmdapi.NewConfig(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.configs[filename] = config
+	f.order = append(f.order, filename)
+	return config, nil
+}
+
+func (f *kubeconfigFiles) write() error {
+	for _, filename := range f.order {
+		err := clientcmd.WriteToFile(*f.configs[filename], filename)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Takes the kubectl lock file for every kubeconfig we might write,
+// in sorted order so that we can't deadlock with kubectl.
+//
+// KUBECONFIG may list files in directories that don't exist. We never
+// write those, except the default file, so we skip them rather than
+// failing, and create the default file's directory.
+//
+// Returns a function that releases the locks.
+func (w kubeconfigWriter) lock() (func(), error) {
+	defaultFile := w.pathOptions.GetDefaultFilename()
+	files := []string{}
+	for _, f := range w.pathOptions.GetLoadingPrecedence() {
+		_, err := os.Stat(filepath.Dir(f))
+		if os.IsNotExist(err) && f != defaultFile {
+			continue
+		}
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	err := os.MkdirAll(filepath.Dir(defaultFile), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("locking kubeconfig: %v", err)
+	}
+
+	locked := []string{}
+	unlock := func() {
+		for _, f := range locked {
+			_ = os.Remove(lockFileName(f))
+		}
+	}
+
+	for _, f := range files {
+		err := lockKubeconfigFile(f, w.lockTimeout)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locked = append(locked, f)
+	}
+	return unlock, nil
+}
+
+func lockFileName(filename string) string {
+	return filename + ".lock"
+}
+
+func lockKubeconfigFile(filename string, timeout time.Duration) error {
+	lockName := lockFileName(filename)
+	err := wait.PollImmediate(kubeconfigLockInterval, timeout, func() (bool, error) {
+		f, err := os.OpenFile(lockName, os.O_CREATE|os.O_EXCL, 0)
+		if err != nil {
+			if os.IsExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, f.Close()
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out waiting for kubeconfig lock %s. "+
+			"If no other process is editing the kubeconfig, delete the lock file and try again", lockName)
+	}
+	if err != nil {
+		return fmt.Errorf("locking kubeconfig: %v", err)
+	}
+	return nil
+}
+
+// Supports the subset of `kubectl config set` properties that
+// yap has a use for.
+func setConfigProperty(config *clientcmdapi.Config, name, value string) error {
+	if name == "current-context" {
+		config.CurrentContext = value
+		return nil
+	}
+
+	// Entry names may contain dots, so the field is everything after the last one.
+	kind, rest, ok := strings.Cut(name, ".")
+	dot := strings.LastIndex(rest, ".")
+	if !ok || dot <= 0 {
+		return fmt.Errorf("unsupported kubeconfig property: %s", name)
+	}
+	entry, field := rest[:dot], rest[dot+1:]
+
+	switch kind {
+	case "clusters":
+		cluster, ok := config.Clusters[entry]
+		if !ok {
+			cluster = clientcmdapi.NewCluster()
+			config.Clusters[entry] = cluster
+		}
+		switch field {
+		case "server":
+			cluster.Server = value
+		case "certificate-authority":
+			cluster.CertificateAuthority = value
+		case "tls-server-name":
+			cluster.TLSServerName = value
+		case "insecure-skip-tls-verify":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %v", name, err)
+			}
+			cluster.InsecureSkipTLSVerify = b
+		default:
+			return fmt.Errorf("unsupported kubeconfig property: %s", name)
+		}
+
+	case "contexts":
+		context, ok := config.Contexts[entry]
+		if !ok {
+			context = clientcmdapi.NewContext()
+			config.Contexts[entry] = context
+		}
+		switch field {
+		case "cluster":
+			context.Cluster = value
+		case "user":
+			context.AuthInfo = value
+		case "namespace":
+			context.Namespace = value
+		default:
+			return fmt.Errorf("unsupported kubeconfig property: %s", name)
+		}
+
+	default:
+		return fmt.Errorf("unsupported kubeconfig property: %s", name)
+	}
+	return nil
 }
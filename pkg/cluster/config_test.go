@@ -0,0 +1,182 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const kindKubeconfig = `apiVersion: v1
+kind: Config
+current-context: kind-kind
+clusters:
+- name: kind-kind
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: kind-kind
+  context:
+    cluster: kind-kind
+    user: kind-kind
+users:
+- name: kind-kind
+  user:
+    token: kind-token
+`
+
+const minikubeKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: minikube
+  cluster:
+    server: https://192.168.49.2:8443
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    user: minikube
+- name: minikube-admin
+  context:
+    cluster: minikube
+    user: minikube-admin
+users:
+- name: minikube
+  user:
+    token: minikube-token
+- name: minikube-admin
+  user:
+    token: admin-token
+`
+
+func TestKubeconfigWriterSetContext(t *testing.T) {
+	f := newKubeconfigFixture(t, kindKubeconfig, minikubeKubeconfig)
+
+	err := f.w.SetContext("minikube")
+	require.NoError(t, err)
+	assert.Equal(t, "minikube", f.load(f.files[0]).CurrentContext)
+
+	// The context stays in the file it came from.
+	assert.Contains(t, f.load(f.files[1]).Contexts, "minikube")
+	assert.NotContains(t, f.load(f.files[0]).Contexts, "minikube")
+
+	err = f.w.SetContext("missing")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `no context exists with the name: "missing"`)
+	}
+}
+
+func TestKubeconfigWriterDeleteContext(t *testing.T) {
+	f := newKubeconfigFixture(t, kindKubeconfig, minikubeKubeconfig)
+
+	err := f.w.DeleteContext("kind-kind")
+	require.NoError(t, err)
+
+	config := f.load(f.files[0])
+	assert.Equal(t, "", config.CurrentContext)
+	assert.NotContains(t, config.Contexts, "kind-kind")
+	assert.NotContains(t, config.Clusters, "kind-kind")
+	assert.NotContains(t, config.AuthInfos, "kind-kind")
+
+	// The minikube cluster is still used by minikube-admin, so only
+	// the user goes away.
+	err = f.w.DeleteContext("minikube")
+	require.NoError(t, err)
+
+	config = f.load(f.files[1])
+	assert.NotContains(t, config.Contexts, "minikube")
+	assert.Contains(t, config.Clusters, "minikube")
+	assert.NotContains(t, config.AuthInfos, "minikube")
+	assert.Contains(t, config.AuthInfos, "minikube-admin")
+}
+
+func TestKubeconfigWriterSetConfig(t *testing.T) {
+	f := newKubeconfigFixture(t, kindKubeconfig, minikubeKubeconfig)
+
+	err := f.w.SetConfig("clusters.minikube.server", "https://minikube:8443")
+	require.NoError(t, err)
+	assert.Equal(t, "https://minikube:8443", f.load(f.files[1]).Clusters["minikube"].Server)
+	assert.Equal(t, "https://127.0.0.1:6443", f.load(f.files[0]).Clusters["kind-kind"].Server)
+
+	err = f.w.SetConfig("clusters.kind-kind.proxy-url", "http://proxy")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unsupported kubeconfig property: clusters.kind-kind.proxy-url")
+	}
+}
+
+func TestKubeconfigWriterLocked(t *testing.T) {
+	f := newKubeconfigFixture(t, kindKubeconfig)
+	f.w.lockTimeout = 100 * time.Millisecond
+
+	lockName := f.files[0] + ".lock"
+	require.NoError(t, os.WriteFile(lockName, nil, 0600))
+
+	err := f.w.SetContext("kind-kind")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out waiting for kubeconfig lock")
+	}
+
+	require.NoError(t, os.Remove(lockName))
+	err = f.w.DeleteContext("kind-kind")
+	require.NoError(t, err)
+
+	_, err = os.Stat(lockName)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestKubeconfigWriterMissingDirectory(t *testing.T) {
+	f := newKubeconfigFixture(t, kindKubeconfig, minikubeKubeconfig)
+	missing := filepath.Join(t.TempDir(), "gone", "config")
+	t.Setenv("KUBECONFIG", strings.Join(append(f.files, missing), string(filepath.ListSeparator)))
+
+	err := f.w.SetContext("minikube")
+	require.NoError(t, err)
+	assert.Equal(t, "minikube", f.load(f.files[0]).CurrentContext)
+	assert.True(t, clientcmd.UseModifyConfigLock)
+
+	_, err = os.Stat(filepath.Dir(missing))
+	assert.True(t, os.IsNotExist(err))
+}
+
+type kubeconfigFixture struct {
+	t     *testing.T
+	files []string
+	w     kubeconfigWriter
+}
+
+func newKubeconfigFixture(t *testing.T, contents ...string) *kubeconfigFixture {
+	dir := t.TempDir()
+	files := []string{}
+	for i, c := range contents {
+		file := filepath.Join(dir, []string{"a.yaml", "b.yaml", "c.yaml"}[i])
+		require.NoError(t, os.WriteFile(file, []byte(c), 0600))
+		files = append(files, file)
+	}
+	t.Setenv("KUBECONFIG", strings.Join(files, string(filepath.ListSeparator)))
+
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.GlobalFile = filepath.Join(dir, "config")
+
+	return &kubeconfigFixture{
+		t:     t,
+		files: files,
+		w: kubeconfigWriter{
+			iostreams:   genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr},
+			pathOptions: pathOptions,
+			lockTimeout: kubeconfigLockTimeout,
+		},
+	}
+}
+
+func (f *kubeconfigFixture) load(file string) *clientcmdapi.Config {
+	config, err := clientcmd.LoadFromFile(file)
+	require.NoError(f.t, err)
+	return config
+}
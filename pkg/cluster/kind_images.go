@@ -0,0 +1,183 @@
+package cluster
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+//go:embed kind_images.yaml
+var defaultKindImagesYAML []byte
+
+// The repository for official kind node images.
+const kindNodeRepo = "kindest/node"
+
+// Tables may list images under this key to use them with any kind version.
+const anyKindVersion = "*"
+
+// kindImageTable maps a kind version to a Kubernetes minor version
+// (e.g., 1.26) to a node image.
+type kindImageTable map[string]map[string]string
+
+func (t kindImageTable) lookup(kindVersion string, k8sVersion semver.Version) string {
+	minor := fmt.Sprintf("%d.%d", k8sVersion.Major, k8sVersion.Minor)
+	if image := t[kindVersion][minor]; image != "" {
+		return image
+	}
+	return t[anyKindVersion][minor]
+}
+
+func (t kindImageTable) hasKindVersion(kindVersion string) bool {
+	_, ok := t[kindVersion]
+	return ok
+}
+
+func parseKindImageTable(data []byte) (kindImageTable, error) {
+	table := kindImageTable{}
+	err := yaml.Unmarshal(data, &table)
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// nodeImageResolver finds a kind node image for a Kubernetes version.
+//
+// Returns an empty string if the resolver doesn't know of a matching image.
+type nodeImageResolver interface {
+	resolveNodeImage(ctx context.Context, kindVersion string, k8sVersion semver.Version) (string, error)
+
+	// Whether the resolver has a list of images for this kind version,
+	// for better error messages.
+	knowsKindVersion(ctx context.Context, kindVersion string) bool
+}
+
+// tableResolver looks up images in a table, like the one embedded in yap.
+type tableResolver struct {
+	table kindImageTable
+}
+
+func newDefaultTableResolver() tableResolver {
+	table, err := parseKindImageTable(defaultKindImagesYAML)
+	if err != nil {
+		// The table is checked by tests, so this should never happen.
+		panic(fmt.Sprintf("parsing embedded kind images: %v", err))
+	}
+	return tableResolver{table: table}
+}
+
+func (r tableResolver) resolveNodeImage(ctx context.Context, kindVersion string, k8sVersion semver.Version) (string, error) {
+	return r.table.lookup(kindVersion, k8sVersion), nil
+}
+
+func (r tableResolver) knowsKindVersion(ctx context.Context, kindVersion string) bool {
+	return r.table.hasKindVersion(kindVersion)
+}
+
+// fileResolver looks up images in a table in a user-supplied file.
+// A missing file is the same as an empty table.
+type fileResolver struct {
+	path string
+}
+
+// The default location of the user's kind image table, or empty if we
+// can't find a home directory.
+func defaultKindImagesFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".yap", "kind-images.yaml")
+}
+
+func (r fileResolver) load() (kindImageTable, error) {
+	if r.path == "" {
+		return kindImageTable{}, nil
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return kindImageTable{}, nil
+		}
+		return nil, err
+	}
+	table, err := parseKindImageTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", r.path, err)
+	}
+	return table, nil
+}
+
+func (r fileResolver) resolveNodeImage(ctx context.Context, kindVersion string, k8sVersion semver.Version) (string, error) {
+	table, err := r.load()
+	if err != nil {
+		return "", err
+	}
+	return table.lookup(kindVersion, k8sVersion), nil
+}
+
+func (r fileResolver) knowsKindVersion(ctx context.Context, kindVersion string) bool {
+	table, err := r.load()
+	if err != nil {
+		return false
+	}
+	return table.hasKindVersion(kindVersion) || table.hasKindVersion(anyKindVersion)
+}
+
+// localImageResolver picks the newest kindest/node image with a matching
+// minor version from the images already pulled into Docker.
+//
+// Useful when kind is newer than yap, and the user has already pulled
+// the images they need.
+type localImageResolver struct {
+	dockerClient dockerClient
+}
+
+func (r localImageResolver) resolveNodeImage(ctx context.Context, kindVersion string, k8sVersion semver.Version) (string, error) {
+	if r.dockerClient == nil {
+		return "", nil
+	}
+
+	images, err := r.dockerClient.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("reference", kindNodeRepo)),
+	})
+	if err != nil {
+		// Not being able to list images shouldn't stop us from
+		// reporting that we don't have an image.
+		klog.V(4).Infof("WARNING: listing local kind images: %v\n", err)
+		return "", nil
+	}
+
+	best := ""
+	var bestVersion semver.Version
+	for _, image := range images {
+		for _, tag := range image.RepoTags {
+			v, ok := strings.CutPrefix(tag, kindNodeRepo+":")
+			if !ok {
+				continue
+			}
+			version, err := semver.ParseTolerant(v)
+			if err != nil || version.Major != k8sVersion.Major || version.Minor != k8sVersion.Minor {
+				continue
+			}
+			if best == "" || version.GT(bestVersion) {
+				best = tag
+				bestVersion = version
+			}
+		}
+	}
+	return best, nil
+}
+
+func (r localImageResolver) knowsKindVersion(ctx context.Context, kindVersion string) bool {
+	return false
+}
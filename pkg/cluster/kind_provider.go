@@ -0,0 +1,308 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	kindcluster "sigs.k8s.io/kind/pkg/cluster"
+	kindversion "sigs.k8s.io/kind/pkg/cmd/kind/version"
+	kindlog "sigs.k8s.io/kind/pkg/log"
+)
+
+// Set YAP_KIND_MODE=cli to manage kind clusters with the kind CLI
+// instead of the kind library built into yap.
+const kindModeEnv = "YAP_KIND_MODE"
+
+// kindProvider creates and deletes kind clusters.
+//
+// Cluster names are the kind names, without the kind- prefix.
+type kindProvider interface {
+	List(ctx context.Context) ([]string, error)
+	Create(ctx context.Context, name string, config *v1alpha4.Cluster, nodeImage string) error
+	Delete(ctx context.Context, name string) error
+
+	// The kind version, e.g., v0.18.0.
+	Version(ctx context.Context) (string, error)
+}
+
+// Prefers the kind library, so that yap doesn't need a kind binary that
+// matches its kind version. Falls back to the kind CLI when asked to, or
+// when the library can't find a container runtime that the CLI might know
+// how to use.
+func newKindProvider(iostreams genericclioptions.IOStreams) (kindProvider, error) {
+	mode := os.Getenv(kindModeEnv)
+	switch mode {
+	case "cli":
+		return newKindCLIProvider(iostreams)
+	case "", "library":
+	default:
+		return nil, fmt.Errorf("invalid %s %q. Must be one of: library, cli", kindModeEnv, mode)
+	}
+
+	provider, err := newKindLibraryProvider(iostreams)
+	if err == nil {
+		return provider, nil
+	}
+
+	cli, cliErr := newKindCLIProvider(iostreams)
+	if cliErr != nil {
+		return nil, err
+	}
+	klog.V(2).Infof("Falling back to the kind CLI: %v", err)
+	return cli, nil
+}
+
+// How long we wait for a kind library call to finish after its
+// context is done.
+const kindCancelGracePeriod = 2 * time.Minute
+
+// The parts of the kind library's Provider that we use.
+type kindLibrary interface {
+	List() ([]string, error)
+	Create(name string, options ...kindcluster.CreateOption) error
+	Delete(name, explicitKubeconfigPath string) error
+}
+
+// kindLibraryProvider manages clusters with the kind library, in-process.
+type kindLibraryProvider struct {
+	library     kindLibrary
+	errOut      io.Writer
+	gracePeriod time.Duration
+
+	// Held while a library call runs, so that a Delete can't start
+	// while a Create that we stopped waiting for is still adding nodes.
+	mu sync.Mutex
+}
+
+func newKindLibraryProvider(iostreams genericclioptions.IOStreams) (*kindLibraryProvider, error) {
+	// Match the kind CLI, which lets users pick the runtime explicitly.
+	var runtime kindcluster.ProviderOption
+	switch os.Getenv("KIND_EXPERIMENTAL_PROVIDER") {
+	case "podman":
+		runtime = kindcluster.ProviderWithPodman()
+	case "docker":
+		runtime = kindcluster.ProviderWithDocker()
+	default:
+		detected, err := kindcluster.DetectNodeProvider()
+		if err != nil {
+			return nil, errors.Wrap(err, "detecting kind node provider")
+		}
+		runtime = detected
+	}
+
+	return &kindLibraryProvider{
+		library: kindcluster.NewProvider(
+			runtime,
+			kindcluster.ProviderWithLogger(newKindLogger(iostreams.ErrOut)),
+		),
+		errOut:      iostreams.ErrOut,
+		gracePeriod: kindCancelGracePeriod,
+	}, nil
+}
+
+func (p *kindLibraryProvider) List(ctx context.Context) ([]string, error) {
+	return p.library.List()
+}
+
+func (p *kindLibraryProvider) Create(ctx context.Context, name string, config *v1alpha4.Cluster, nodeImage string) error {
+	options := []kindcluster.CreateOption{
+		kindcluster.CreateWithV1Alpha4Config(config),
+		kindcluster.CreateWithDisplayUsage(true),
+		kindcluster.CreateWithDisplaySalutation(true),
+	}
+	if nodeImage != "" {
+		options = append(options, kindcluster.CreateWithNodeImage(nodeImage))
+	}
+	return p.run(ctx, "creating the cluster", func() error {
+		return p.library.Create(name, options...)
+	})
+}
+
+func (p *kindLibraryProvider) Delete(ctx context.Context, name string) error {
+	return p.run(ctx, "deleting the cluster", func() error {
+		return p.library.Delete(name, "")
+	})
+}
+
+// The kind library doesn't take a context, and can't be interrupted.
+//
+// When the context is done, we wait for the call to finish anyway, so that
+// nothing deletes a cluster that kind is still adding nodes to. If it takes
+// longer than the grace period, we give up with errStillRunning.
+func (p *kindLibraryProvider) run(ctx context.Context, action string, f func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		done <- f()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	_, _ = fmt.Fprintf(p.errOut, "Waiting up to %s for kind to finish %s...\n", p.gracePeriod, action)
+	select {
+	case <-done:
+		return ctx.Err()
+	case <-time.After(p.gracePeriod):
+		return errors.Wrapf(errStillRunning, "%v, but kind didn't finish %s within %s", ctx.Err(), action, p.gracePeriod)
+	}
+}
+
+func (p *kindLibraryProvider) Version(ctx context.Context) (string, error) {
+	return "v" + kindversion.Version(), nil
+}
+
+// kindCLIProvider manages clusters by shelling out to the kind CLI.
+type kindCLIProvider struct {
+	iostreams genericclioptions.IOStreams
+}
+
+func newKindCLIProvider(iostreams genericclioptions.IOStreams) (*kindCLIProvider, error) {
+	_, err := exec.LookPath("kind")
+	if err != nil {
+		return nil, fmt.Errorf("kind not installed. Please install kind with these instructions: https://kind.sigs.k8s.io/")
+	}
+	return &kindCLIProvider{iostreams: iostreams}, nil
+}
+
+func (p *kindCLIProvider) List(ctx context.Context) ([]string, error) {
+	buf := bytes.NewBuffer(nil)
+	cmd := exec.CommandContext(ctx, "kind", "get", "clusters")
+	cmd.Stdout = buf
+	cmd.Stderr = p.iostreams.ErrOut
+	err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "kind get clusters")
+	}
+
+	result := []string{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+func (p *kindCLIProvider) Create(ctx context.Context, name string, config *v1alpha4.Cluster, nodeImage string) error {
+	args := []string{"create", "cluster", "--name", name}
+	if nodeImage != "" {
+		args = append(args, "--image", nodeImage)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	encoder := yaml.NewEncoder(buf)
+	err := encoder.Encode(config)
+	if err != nil {
+		return err
+	}
+
+	args = append(args, "--config", "-")
+
+	cmd := exec.CommandContext(ctx, "kind", args...)
+	cmd.Stdout = p.iostreams.Out
+	cmd.Stderr = p.iostreams.ErrOut
+	cmd.Stdin = buf
+	return cmd.Run()
+}
+
+func (p *kindCLIProvider) Delete(ctx context.Context, name string) error {
+	cmd := exec.CommandContext(ctx, "kind", "delete", "cluster", "--name", name)
+	cmd.Stdout = p.iostreams.Out
+	cmd.Stderr = p.iostreams.ErrOut
+	cmd.Stdin = p.iostreams.In
+	return cmd.Run()
+}
+
+func (p *kindCLIProvider) Version(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "kind", "version")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrap(err, "kind version")
+	}
+	return parseKindVersion(string(out))
+}
+
+// Finds the version in `kind version` output, e.g.,
+// "kind v0.18.0 go1.20.2 darwin/arm64".
+func parseKindVersion(out string) (string, error) {
+	for _, field := range strings.Fields(out) {
+		if strings.HasPrefix(field, "v") && strings.Count(field, ".") >= 2 {
+			return field, nil
+		}
+	}
+	return "", fmt.Errorf("parsing kind version output: %s", out)
+}
+
+// kindLogger sends kind's progress messages to our output, and its debug
+// logs to klog.
+type kindLogger struct {
+	out io.Writer
+}
+
+func newKindLogger(out io.Writer) kindLogger {
+	return kindLogger{out: out}
+}
+
+func (l kindLogger) Warn(message string) {
+	_, _ = fmt.Fprintf(l.out, "WARNING: %s\n", message)
+}
+
+func (l kindLogger) Warnf(format string, args ...interface{}) {
+	l.Warn(fmt.Sprintf(format, args...))
+}
+
+func (l kindLogger) Error(message string) {
+	_, _ = fmt.Fprintf(l.out, "ERROR: %s\n", message)
+}
+
+func (l kindLogger) Errorf(format string, args ...interface{}) {
+	l.Error(fmt.Sprintf(format, args...))
+}
+
+func (l kindLogger) V(level kindlog.Level) kindlog.InfoLogger {
+	return kindInfoLogger{out: l.out, level: level}
+}
+
+type kindInfoLogger struct {
+	out   io.Writer
+	level kindlog.Level
+}
+
+func (l kindInfoLogger) Info(message string) {
+	if l.level == 0 {
+		_, _ = fmt.Fprintln(l.out, message)
+		return
+	}
+	klog.V(klog.Level(l.level)).Info(message)
+}
+
+func (l kindInfoLogger) Infof(format string, args ...interface{}) {
+	l.Info(fmt.Sprintf(format, args...))
+}
+
+func (l kindInfoLogger) Enabled() bool {
+	if l.level == 0 {
+		return true
+	}
+	return bool(klog.V(klog.Level(l.level)).Enabled())
+}
@@ -0,0 +1,464 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	clusterid "github.com/pseudonator/yap/pkg/internal/cluster"
+	cexec "github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+// A lifecycle stops and starts a single cluster in place, without deleting it.
+//
+// Unlike a Machine, which may be shared by every cluster on the same
+// Docker VM, a lifecycle is bound to one cluster.
+type lifecycle interface {
+	Stop(ctx context.Context) error
+	Start(ctx context.Context) error
+	Pause(ctx context.Context) error
+
+	// Returns an empty phase if the cluster doesn't exist.
+	Phase(ctx context.Context) (api.ClusterPhase, error)
+}
+
+func (c *Controller) lifecycle(ctx context.Context, name string, product clusterid.Product) (lifecycle, error) {
+	switch product {
+	case clusterid.ProductMinikube:
+		return &minikubeLifecycle{iostreams: c.iostreams, runner: c.runner, name: name}, nil
+	case clusterid.ProductK3D:
+		return &k3dLifecycle{iostreams: c.iostreams, runner: c.runner, name: strings.TrimPrefix(name, "k3d-")}, nil
+	case clusterid.ProductColima:
+		return &colimaLifecycle{machine: newColimaMachine(c.iostreams, c.runner, name)}, nil
+	case clusterid.ProductKIND:
+		dockerClient, err := c.getDockerClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &kindLifecycle{iostreams: c.iostreams, dockerClient: dockerClient, name: strings.TrimPrefix(name, "kind-")}, nil
+	}
+	return unsupportedLifecycle{product: product}, nil
+}
+
+// Stop shuts down the cluster's machines or containers, keeping its state.
+func (c *Controller) Stop(ctx context.Context, name string) error {
+	l, err := c.existingLifecycle(ctx, name)
+	if err != nil {
+		return err
+	}
+	return l.Stop(ctx)
+}
+
+// Pause freezes the cluster's processes, keeping them in memory.
+func (c *Controller) Pause(ctx context.Context, name string) error {
+	l, err := c.existingLifecycle(ctx, name)
+	if err != nil {
+		return err
+	}
+	return l.Pause(ctx)
+}
+
+// Start brings a stopped or paused cluster back, and waits for
+// the apiserver to respond.
+func (c *Controller) Start(ctx context.Context, name string) error {
+	l, err := c.existingLifecycle(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	err = l.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Some products rewrite the kubeconfig on start.
+	err = c.reloadConfigs()
+	if err != nil {
+		return err
+	}
+	return c.waitForHealthCheckAfterCreate(ctx, &api.Cluster{Name: name})
+}
+
+// Looks up the cluster in the kubeconfig, without health-checking it,
+// since it may well be stopped.
+func (c *Controller) existingLifecycle(ctx context.Context, name string) (lifecycle, error) {
+	config := c.configCopy()
+	ct, ok := config.Contexts[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(groupResource, name)
+	}
+	configCluster, ok := config.Clusters[ct.Cluster]
+	if !ok {
+		return nil, apierrors.NewNotFound(groupResource, name)
+	}
+
+	product := clusterid.ProductFromContext(ct, configCluster)
+	return c.lifecycle(ctx, name, product)
+}
+
+// Fills in the phase of a cluster that failed its health check,
+// so users can tell a stopped cluster from a broken one.
+func (c *Controller) populatePhase(ctx context.Context, cluster *api.Cluster) error {
+	l, err := c.lifecycle(ctx, cluster.Name, clusterid.Product(cluster.Product))
+	if err != nil {
+		return err
+	}
+	phase, err := l.Phase(ctx)
+	if err != nil {
+		return err
+	}
+	cluster.Status.Phase = phase
+	return nil
+}
+
+type unsupportedLifecycle struct {
+	product clusterid.Product
+}
+
+func (l unsupportedLifecycle) Stop(ctx context.Context) error {
+	return fmt.Errorf("cluster type %s does not support stop", l.product)
+}
+
+func (l unsupportedLifecycle) Start(ctx context.Context) error {
+	return fmt.Errorf("cluster type %s does not support start", l.product)
+}
+
+func (l unsupportedLifecycle) Pause(ctx context.Context) error {
+	return fmt.Errorf("cluster type %s does not support pause", l.product)
+}
+
+func (l unsupportedLifecycle) Phase(ctx context.Context) (api.ClusterPhase, error) {
+	return "", nil
+}
+
+type minikubeLifecycle struct {
+	iostreams genericclioptions.IOStreams
+	runner    cexec.CmdRunner
+	name      string
+}
+
+func (l *minikubeLifecycle) Stop(ctx context.Context) error {
+	err := l.runner.RunIO(ctx, l.iostreams, "minikube", "stop", "-p", l.name)
+	if err != nil {
+		return errors.Wrap(err, "stopping minikube")
+	}
+	return nil
+}
+
+func (l *minikubeLifecycle) Pause(ctx context.Context) error {
+	err := l.runner.RunIO(ctx, l.iostreams, "minikube", "pause", "-p", l.name)
+	if err != nil {
+		return errors.Wrap(err, "pausing minikube")
+	}
+	return nil
+}
+
+func (l *minikubeLifecycle) Start(ctx context.Context) error {
+	phase, err := l.Phase(ctx)
+	if err != nil {
+		return err
+	}
+
+	if phase == api.ClusterPhasePaused {
+		err = l.runner.RunIO(ctx, l.iostreams, "minikube", "unpause", "-p", l.name)
+		if err != nil {
+			return errors.Wrap(err, "unpausing minikube")
+		}
+		return nil
+	}
+
+	err = l.runner.RunIO(ctx, l.iostreams, "minikube", "start", "-p", l.name)
+	if err != nil {
+		return errors.Wrap(err, "starting minikube")
+	}
+	return nil
+}
+
+func (l *minikubeLifecycle) Phase(ctx context.Context) (api.ClusterPhase, error) {
+	out := bytes.NewBuffer(nil)
+
+	// Ignore errors. `minikube status` returns a non-zero exit code when
+	// the cluster is stopped.
+	_ = l.runner.RunIO(ctx, genericclioptions.IOStreams{Out: out, ErrOut: io.Discard},
+		"minikube", "status", "-p", l.name, "-o", "json")
+
+	status := minikubeStatus{}
+	err := json.NewDecoder(out).Decode(&status)
+	if err != nil {
+		return "", errors.Wrap(err, "minikube status")
+	}
+
+	// A running host with a stopped apiserver isn't paused. Only
+	// `minikube start` brings the apiserver back.
+	switch {
+	case status.Host == "Stopped" || status.APIServer == "Stopped":
+		return api.ClusterPhaseStopped, nil
+	case status.APIServer == "Paused":
+		return api.ClusterPhasePaused, nil
+	case status.Host == "Running":
+		return api.ClusterPhaseRunning, nil
+	}
+	return "", nil
+}
+
+type k3dLifecycle struct {
+	iostreams genericclioptions.IOStreams
+	runner    cexec.CmdRunner
+
+	// The k3d cluster name, without the k3d- prefix.
+	name string
+}
+
+// Each element of `k3d cluster list -o json`.
+type k3dClusterListEntry struct {
+	Name           string `json:"name"`
+	ServersCount   int    `json:"serversCount"`
+	ServersRunning int    `json:"serversRunning"`
+}
+
+func (l *k3dLifecycle) Stop(ctx context.Context) error {
+	err := l.runner.RunIO(ctx, l.iostreams, "k3d", "cluster", "stop", l.name)
+	if err != nil {
+		return errors.Wrap(err, "stopping k3d cluster")
+	}
+	return nil
+}
+
+func (l *k3dLifecycle) Start(ctx context.Context) error {
+	err := l.runner.RunIO(ctx, l.iostreams, "k3d", "cluster", "start", l.name)
+	if err != nil {
+		return errors.Wrap(err, "starting k3d cluster")
+	}
+	return nil
+}
+
+func (l *k3dLifecycle) Pause(ctx context.Context) error {
+	return fmt.Errorf("cluster type k3d does not support pause. Use stop instead")
+}
+
+func (l *k3dLifecycle) Phase(ctx context.Context) (api.ClusterPhase, error) {
+	out := bytes.NewBuffer(nil)
+	err := l.runner.RunIO(ctx, genericclioptions.IOStreams{Out: out, ErrOut: io.Discard},
+		"k3d", "cluster", "list", "-o", "json")
+	if err != nil {
+		return "", errors.Wrap(err, "k3d cluster list")
+	}
+
+	entries := []k3dClusterListEntry{}
+	err = json.NewDecoder(out).Decode(&entries)
+	if err != nil {
+		return "", errors.Wrap(err, "k3d cluster list")
+	}
+
+	for _, entry := range entries {
+		if entry.Name != l.name {
+			continue
+		}
+		if entry.ServersRunning == 0 {
+			return api.ClusterPhaseStopped, nil
+		}
+		return api.ClusterPhaseRunning, nil
+	}
+	return "", nil
+}
+
+type colimaLifecycle struct {
+	machine *colimaMachine
+}
+
+func (l *colimaLifecycle) Stop(ctx context.Context) error {
+	m := l.machine
+	err := m.runner.RunIO(ctx, m.iostreams, "colima", "stop", "-p", m.name)
+	if err != nil {
+		return errors.Wrap(err, "stopping colima")
+	}
+	return nil
+}
+
+func (l *colimaLifecycle) Start(ctx context.Context) error {
+	m := l.machine
+	err := m.runner.RunIO(ctx, m.iostreams, "colima", "start", "-p", m.name)
+	if err != nil {
+		return errors.Wrap(err, "starting colima")
+	}
+	return nil
+}
+
+func (l *colimaLifecycle) Pause(ctx context.Context) error {
+	return fmt.Errorf("cluster type colima does not support pause. Use stop instead")
+}
+
+func (l *colimaLifecycle) Phase(ctx context.Context) (api.ClusterPhase, error) {
+	entry, err := l.machine.listEntry(ctx)
+	if err != nil || entry == nil {
+		return "", err
+	}
+	switch entry.Status {
+	case "Stopped":
+		return api.ClusterPhaseStopped, nil
+	case "Running":
+		return api.ClusterPhaseRunning, nil
+	}
+	return "", nil
+}
+
+// kindLifecycle stops and starts the kind node containers directly,
+// since kind itself has no stop command.
+type kindLifecycle struct {
+	iostreams    genericclioptions.IOStreams
+	dockerClient dockerClient
+
+	// The kind cluster name, without the kind- prefix.
+	name string
+}
+
+// Kind labels each node container with its cluster and role.
+const (
+	kindClusterLabel = "io.x-k8s.kind.cluster"
+	kindRoleLabel    = "io.x-k8s.kind.role"
+)
+
+// The order to start nodes in. Workers can't join until
+// the control plane (and its load balancer) is up.
+var kindRoleStartOrder = map[string]int{
+	"external-load-balancer": 0,
+	"control-plane":          1,
+	"worker":                 2,
+}
+
+// Lists the node containers, in the order we should start them.
+func (l *kindLifecycle) nodes(ctx context.Context) ([]types.Container, error) {
+	containers, err := l.dockerClient.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", kindClusterLabel, l.name))),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing kind nodes")
+	}
+
+	sort.SliceStable(containers, func(i, j int) bool {
+		return kindRoleStartOrder[containers[i].Labels[kindRoleLabel]] <
+			kindRoleStartOrder[containers[j].Labels[kindRoleLabel]]
+	})
+	return containers, nil
+}
+
+func (l *kindLifecycle) requireNodes(ctx context.Context) ([]types.Container, error) {
+	nodes, err := l.nodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no node containers found for kind cluster %s", l.name)
+	}
+	return nodes, nil
+}
+
+func (l *kindLifecycle) Stop(ctx context.Context) error {
+	nodes, err := l.requireNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Stop in the reverse order that we start.
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		if node.State == "paused" {
+			err := l.dockerClient.ContainerUnpause(ctx, node.ID)
+			if err != nil {
+				return errors.Wrapf(err, "unpausing kind node %s", containerName(node))
+			}
+		}
+		if node.State == "exited" || node.State == "created" {
+			continue
+		}
+		err := l.dockerClient.ContainerStop(ctx, node.ID, nil)
+		if err != nil {
+			return errors.Wrapf(err, "stopping kind node %s", containerName(node))
+		}
+	}
+	return nil
+}
+
+func (l *kindLifecycle) Start(ctx context.Context) error {
+	nodes, err := l.requireNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		switch node.State {
+		case "running":
+			continue
+		case "paused":
+			err = l.dockerClient.ContainerUnpause(ctx, node.ID)
+		default:
+			err = l.dockerClient.ContainerStart(ctx, node.ID, types.ContainerStartOptions{})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "starting kind node %s", containerName(node))
+		}
+	}
+	return nil
+}
+
+func (l *kindLifecycle) Pause(ctx context.Context) error {
+	nodes, err := l.requireNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if node.State != "running" {
+			continue
+		}
+		err := l.dockerClient.ContainerPause(ctx, node.ID)
+		if err != nil {
+			return errors.Wrapf(err, "pausing kind node %s", containerName(node))
+		}
+	}
+	return nil
+}
+
+func (l *kindLifecycle) Phase(ctx context.Context) (api.ClusterPhase, error) {
+	nodes, err := l.nodes(ctx)
+	if err != nil || len(nodes) == 0 {
+		return "", err
+	}
+
+	running, paused := 0, 0
+	for _, node := range nodes {
+		switch node.State {
+		case "running":
+			running++
+		case "paused":
+			paused++
+		}
+	}
+
+	switch {
+	case paused > 0:
+		return api.ClusterPhasePaused, nil
+	case running == 0:
+		return api.ClusterPhaseStopped, nil
+	}
+	return api.ClusterPhaseRunning, nil
+}
+
+func containerName(c types.Container) string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
@@ -0,0 +1,201 @@
+package cluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+func TestKindLifecycle(t *testing.T) {
+	f := newFixture(t)
+	f.addKindContext()
+	f.dockerClient.containers = []types.Container{
+		kindNode("worker-1", "worker", "running"),
+		kindNode("control-plane", "control-plane", "running"),
+		kindNode("lb", "external-load-balancer", "running"),
+		kindNode("other", "control-plane", "running"),
+	}
+	f.dockerClient.containers[3].Labels[kindClusterLabel] = "other"
+
+	ctx := context.Background()
+	err := f.controller.Stop(ctx, "kind-kind")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stop worker-1", "stop control-plane", "stop lb"}, f.dockerClient.actions)
+
+	for i := range f.dockerClient.containers {
+		f.dockerClient.containers[i].State = "exited"
+	}
+	l, err := f.controller.existingLifecycle(ctx, "kind-kind")
+	require.NoError(t, err)
+	phase, err := l.Phase(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterPhaseStopped, phase)
+
+	f.dockerClient.actions = nil
+	err = l.Start(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start lb", "start control-plane", "start worker-1"}, f.dockerClient.actions)
+}
+
+func TestKindLifecyclePause(t *testing.T) {
+	f := newFixture(t)
+	f.addKindContext()
+	f.dockerClient.containers = []types.Container{
+		kindNode("control-plane", "control-plane", "running"),
+		kindNode("worker-1", "worker", "running"),
+	}
+
+	ctx := context.Background()
+	err := f.controller.Pause(ctx, "kind-kind")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pause control-plane", "pause worker-1"}, f.dockerClient.actions)
+
+	for i := range f.dockerClient.containers {
+		f.dockerClient.containers[i].State = "paused"
+	}
+	cluster := &api.Cluster{Name: "kind-kind", Product: "kind"}
+	err = f.controller.populatePhase(ctx, cluster)
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterPhasePaused, cluster.Status.Phase)
+
+	// Start unpauses paused nodes rather than starting them.
+	f.dockerClient.actions = nil
+	l, err := f.controller.existingLifecycle(ctx, "kind-kind")
+	require.NoError(t, err)
+	err = l.Start(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unpause control-plane", "unpause worker-1"}, f.dockerClient.actions)
+}
+
+func TestKindLifecycleNoNodes(t *testing.T) {
+	f := newFixture(t)
+	f.addKindContext()
+
+	err := f.controller.Stop(context.Background(), "kind-kind")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no node containers found for kind cluster kind")
+	}
+}
+
+func TestMinikubeLifecycle(t *testing.T) {
+	f := newFixture(t)
+	f.config.Contexts["minikube"] = &clientcmdapi.Context{Cluster: "minikube"}
+	f.config.Clusters["minikube"] = &clientcmdapi.Cluster{Server: "https://192.168.49.2:8443"}
+	require.NoError(t, f.controller.reloadConfigs())
+
+	status := `{"Host":"Running","APIServer":"Paused"}`
+	commands := []string{}
+	f.controller.runner = exec.NewFakeCmdRunner(func(argv []string) string {
+		commands = append(commands, strings.Join(argv, " "))
+		if argv[1] == "status" {
+			return status
+		}
+		return ""
+	})
+
+	ctx := context.Background()
+	l, err := f.controller.existingLifecycle(ctx, "minikube")
+	require.NoError(t, err)
+
+	phase, err := l.Phase(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterPhasePaused, phase)
+
+	// A paused cluster is unpaused, not restarted.
+	commands = nil
+	require.NoError(t, l.Start(ctx))
+	assert.Equal(t, "minikube unpause -p minikube", commands[len(commands)-1])
+
+	status = `{"Host":"Stopped","APIServer":"Stopped"}`
+	commands = nil
+	require.NoError(t, l.Start(ctx))
+	assert.Equal(t, "minikube start -p minikube", commands[len(commands)-1])
+
+	// A stopped apiserver on a running host needs a start, not an unpause.
+	status = `{"Host":"Running","APIServer":"Stopped"}`
+	phase, err = l.Phase(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterPhaseStopped, phase)
+
+	commands = nil
+	require.NoError(t, l.Start(ctx))
+	assert.Equal(t, "minikube start -p minikube", commands[len(commands)-1])
+
+	commands = nil
+	require.NoError(t, l.Stop(ctx))
+	assert.Equal(t, []string{"minikube stop -p minikube"}, commands)
+}
+
+func TestK3DLifecycle(t *testing.T) {
+	f := newFixture(t)
+	f.config.Contexts["k3d-k3s-default"] = &clientcmdapi.Context{Cluster: "k3d-k3s-default"}
+	f.config.Clusters["k3d-k3s-default"] = &clientcmdapi.Cluster{Server: "https://0.0.0.0:6443"}
+	require.NoError(t, f.controller.reloadConfigs())
+
+	runner := exec.NewFakeCmdRunner(func(argv []string) string {
+		if argv[2] == "list" {
+			return `[{"name":"k3s-default","serversCount":1,"serversRunning":0}]`
+		}
+		return ""
+	})
+	f.controller.runner = runner
+
+	ctx := context.Background()
+	l, err := f.controller.existingLifecycle(ctx, "k3d-k3s-default")
+	require.NoError(t, err)
+
+	phase, err := l.Phase(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterPhaseStopped, phase)
+
+	require.NoError(t, l.Stop(ctx))
+	assert.Equal(t, []string{"k3d", "cluster", "stop", "k3s-default"}, runner.LastArgs)
+
+	err = l.Pause(ctx)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not support pause")
+	}
+}
+
+func TestLifecycleUnsupported(t *testing.T) {
+	f := newFixture(t)
+	err := f.controller.Stop(context.Background(), "microk8s")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cluster type microk8s does not support stop")
+	}
+}
+
+func TestLifecycleNotFound(t *testing.T) {
+	f := newFixture(t)
+	err := f.controller.Start(context.Background(), "dunkees")
+	if assert.Error(t, err) {
+		assert.True(t, errors.IsNotFound(err))
+	}
+}
+
+func (f *fixture) addKindContext() {
+	f.config.Contexts["kind-kind"] = &clientcmdapi.Context{Cluster: "kind-kind"}
+	f.config.Clusters["kind-kind"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:6443"}
+	require.NoError(f.t, f.controller.reloadConfigs())
+}
+
+func kindNode(id, role, state string) types.Container {
+	return types.Container{
+		ID:    id,
+		Names: []string{"/kind-" + id},
+		State: state,
+		Labels: map[string]string{
+			kindClusterLabel: "kind",
+			kindRoleLabel:    role,
+		},
+	}
+}
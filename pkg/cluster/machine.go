@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/mitchellh/go-homedir"
@@ -46,6 +48,29 @@ func (m unknownMachine) Restart(ctx context.Context, desired, existing *api.Clus
 	return fmt.Errorf("cluster type %s not configurable", desired.Product)
 }
 
+// Cloud clusters run on machines managed by the cloud provider,
+// so there's no local Docker machine to set up.
+type cloudMachine struct {
+	product clusterid.Product
+}
+
+func (m cloudMachine) EnsureExists(ctx context.Context) error {
+	return nil
+}
+
+// The CPUs are spread across a node pool, so we don't report a count.
+func (m cloudMachine) CPUs(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m cloudMachine) Restart(ctx context.Context, desired, existing *api.Cluster) error {
+	if existing.Status.CreationTimestamp.Time.IsZero() {
+		// The cluster hasn't been created yet, so there's nothing to restart.
+		return nil
+	}
+	return fmt.Errorf("cluster type %s does not support minCPUs. Resize the node group instead", m.product)
+}
+
 type sleeper func(dur time.Duration)
 
 type d4mClient interface {
@@ -158,30 +183,37 @@ func (m *dockerMachine) Restart(ctx context.Context, desired, existing *api.Clus
 		}
 
 		if k8sChanged || cpuChanged {
-			err := m.d4m.writeSettings(ctx, settings)
-			if err != nil {
-				return err
-			}
-
-			dur := 120 * time.Second
-			_, _ = fmt.Fprintf(m.iostreams.ErrOut,
-				"Applied new Docker Desktop settings. Waiting %s for Docker Desktop to restart...\n",
-				duration.ShortHumanDuration(dur))
+			return m.writeSettingsAndWait(ctx, settings)
+		}
+	}
 
-			// Sleep for short time to ensure the write takes effect.
-			m.sleep(2 * time.Second)
+	return nil
+}
 
-			err = wait.Poll(time.Second, dur, func() (bool, error) {
-				_, err := m.dockerClient.ServerVersion(ctx)
-				isSuccess := err == nil
-				return isSuccess, nil
-			})
-			if err != nil {
-				return errors.Wrap(err, "Docker Desktop restart timeout")
-			}
-		}
+// Writes new Docker Desktop settings, and waits for Docker Desktop to
+// restart with them.
+func (m *dockerMachine) writeSettingsAndWait(ctx context.Context, settings map[string]interface{}) error {
+	err := m.d4m.writeSettings(ctx, settings)
+	if err != nil {
+		return err
 	}
 
+	dur := 120 * time.Second
+	_, _ = fmt.Fprintf(m.iostreams.ErrOut,
+		"Applied new Docker Desktop settings. Waiting %s for Docker Desktop to restart...\n",
+		duration.ShortHumanDuration(dur))
+
+	// Sleep for short time to ensure the write takes effect.
+	m.sleep(2 * time.Second)
+
+	err = wait.Poll(time.Second, dur, func() (bool, error) {
+		_, err := m.dockerClient.ServerVersion(ctx)
+		isSuccess := err == nil
+		return isSuccess, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "Docker Desktop restart timeout")
+	}
 	return nil
 }
 
@@ -279,3 +311,136 @@ type minikubeStatus struct {
 	Host      string
 	APIServer string
 }
+
+// colimaMachine manages the Lima VM behind a colima profile.
+//
+// Like minikube, the colima VM can be stopped and started independently of
+// the cluster, and the VM size can be changed on restart.
+type colimaMachine struct {
+	iostreams genericclioptions.IOStreams
+	runner    cexec.CmdRunner
+	name      string
+}
+
+func newColimaMachine(iostreams genericclioptions.IOStreams, runner cexec.CmdRunner, name string) *colimaMachine {
+	return &colimaMachine{
+		iostreams: iostreams,
+		runner:    runner,
+		name:      name,
+	}
+}
+
+// The output of `colima status --json`.
+type colimaStatus struct {
+	CPU int `json:"cpu"`
+}
+
+// Each line of `colima list --json`.
+type colimaListEntry struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	CPUs   int    `json:"cpus"`
+}
+
+func (m *colimaMachine) CPUs(ctx context.Context) (int, error) {
+	out := bytes.NewBuffer(nil)
+	err := m.runner.RunIO(ctx, genericclioptions.IOStreams{Out: out, ErrOut: io.Discard},
+		"colima", "status", "-p", m.name, "--json")
+	if err == nil {
+		status := colimaStatus{}
+		err = json.NewDecoder(out).Decode(&status)
+		if err == nil && status.CPU != 0 {
+			return status.CPU, nil
+		}
+	}
+
+	// `colima status` fails when the VM is stopped, but `colima list`
+	// still knows how big the VM is.
+	entry, err := m.listEntry(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if entry == nil {
+		return 0, fmt.Errorf("colima profile %q not found", m.name)
+	}
+	return entry.CPUs, nil
+}
+
+func (m *colimaMachine) EnsureExists(ctx context.Context) error {
+	entry, err := m.listEntry(ctx)
+	if err != nil {
+		return err
+	}
+
+	// If the profile doesn't exist, the admin will create it.
+	if entry == nil || entry.Status != "Stopped" {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(m.iostreams.ErrOut, "Cluster %q exists but is stopped. Starting...\n", m.name)
+	err = m.runner.RunIO(ctx, m.iostreams, "colima", "start", "-p", m.name)
+	if err != nil {
+		return errors.Wrap(err, "starting colima")
+	}
+	return nil
+}
+
+func (m *colimaMachine) Restart(ctx context.Context, desired, existing *api.Cluster) error {
+	if existing.Status.CreationTimestamp.Time.IsZero() {
+		// The admin sets the VM size when it creates the profile.
+		return nil
+	}
+
+	args := []string{"start", "-p", m.name}
+	if desired.MinCPUs != 0 {
+		args = append(args, fmt.Sprintf("--cpu=%d", desired.MinCPUs))
+	}
+	if desired.Colima != nil && desired.Colima.Memory != 0 {
+		args = append(args, fmt.Sprintf("--memory=%d", desired.Colima.Memory))
+	}
+
+	_, _ = fmt.Fprintf(m.iostreams.ErrOut, "Restarting colima profile %q to change VM size...\n", m.name)
+	err := m.runner.RunIO(ctx, m.iostreams, "colima", "stop", "-p", m.name)
+	if err != nil {
+		return errors.Wrap(err, "stopping colima")
+	}
+
+	err = m.runner.RunIO(ctx, m.iostreams, "colima", args...)
+	if err != nil {
+		return errors.Wrap(err, "starting colima")
+	}
+	return nil
+}
+
+// Finds this profile in `colima list`. Returns nil if it doesn't exist.
+func (m *colimaMachine) listEntry(ctx context.Context) (*colimaListEntry, error) {
+	out := bytes.NewBuffer(nil)
+	err := m.runner.RunIO(ctx, genericclioptions.IOStreams{Out: out, ErrOut: m.iostreams.ErrOut},
+		"colima", "list", "--json")
+	if err != nil {
+		return nil, errors.Wrap(err, "colima list")
+	}
+
+	profile := colimaProfileName(m.name)
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		entry := colimaListEntry{}
+		err := decoder.Decode(&entry)
+		if err != nil {
+			return nil, errors.Wrap(err, "colima list")
+		}
+		if colimaProfileName(entry.Name) == profile {
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// Colima accepts profile names with or without the colima- prefix,
+// and calls the colima profile "default".
+func colimaProfileName(name string) string {
+	if name == "colima" {
+		return "default"
+	}
+	return strings.TrimPrefix(name, "colima-")
+}
@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/internal/exec"
+)
+
+const colimaListJSON = `{"name":"default","status":"Running","arch":"aarch64","cpus":2,"memory":2147483648,"disk":64424509440,"runtime":"docker"}
+{"name":"dev","status":"Stopped","arch":"aarch64","cpus":4,"memory":8589934592,"disk":64424509440,"runtime":"containerd+k3s"}
+`
+
+func TestColimaMachineCPUsRunning(t *testing.T) {
+	f := newColimaMachineFixture("colima", func(argv []string) string {
+		if argv[1] == "status" {
+			return `{"display_name":"colima","driver":"QEMU","arch":"aarch64","runtime":"docker","cpu":2,"memory":2147483648}`
+		}
+		return colimaListJSON
+	})
+
+	cpus, err := f.m.CPUs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, cpus)
+}
+
+func TestColimaMachineCPUsStopped(t *testing.T) {
+	f := newColimaMachineFixture("colima-dev", func(argv []string) string {
+		if argv[1] == "status" {
+			return ""
+		}
+		return colimaListJSON
+	})
+
+	cpus, err := f.m.CPUs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, cpus)
+}
+
+func TestColimaMachineStartsStoppedProfile(t *testing.T) {
+	f := newColimaMachineFixture("colima-dev", func(argv []string) string {
+		return colimaListJSON
+	})
+
+	err := f.m.EnsureExists(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"colima", "start", "-p", "colima-dev"}, f.runner.LastArgs)
+	assert.Contains(t, f.errOut.String(), `Cluster "colima-dev" exists but is stopped. Starting...`)
+}
+
+func TestColimaMachineMissingProfile(t *testing.T) {
+	f := newColimaMachineFixture("colima-new", func(argv []string) string {
+		return colimaListJSON
+	})
+
+	err := f.m.EnsureExists(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"colima", "list", "--json"}, f.runner.LastArgs)
+}
+
+func TestColimaMachineRestart(t *testing.T) {
+	calls := [][]string{}
+	f := newColimaMachineFixture("colima-dev", func(argv []string) string {
+		calls = append(calls, argv)
+		return ""
+	})
+
+	err := f.m.Restart(context.Background(), &api.Cluster{
+		Name:    "colima-dev",
+		MinCPUs: 6,
+		Colima:  &api.ColimaCluster{Memory: 8},
+	}, &api.Cluster{
+		Name: "colima-dev",
+		Status: api.ClusterStatus{
+			CreationTimestamp: metav1.Time{Time: time.Now()},
+			CPUs:              4,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		[]string{"colima", "stop", "-p", "colima-dev"},
+		[]string{"colima", "start", "-p", "colima-dev", "--cpu=6", "--memory=8"},
+	}, calls)
+}
+
+type colimaMachineFixture struct {
+	runner *exec.FakeCmdRunner
+	errOut *strings.Builder
+	m      *colimaMachine
+}
+
+func newColimaMachineFixture(name string, handler func(argv []string) string) *colimaMachineFixture {
+	errOut := &strings.Builder{}
+	iostreams := genericclioptions.IOStreams{Out: os.Stdout, ErrOut: errOut}
+	runner := exec.NewFakeCmdRunner(handler)
+	return &colimaMachineFixture{
+		runner: runner,
+		errOut: errOut,
+		m:      newColimaMachine(iostreams, runner, name),
+	}
+}
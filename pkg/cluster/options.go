@@ -1,29 +1,131 @@
 package cluster
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 
 	"github.com/pseudonator/yap/pkg/api"
 )
 
 type ListOptions struct {
 	FieldSelector string
+	LabelSelector string
+}
+
+// The fields we know without talking to the cluster.
+var kubeconfigFields = map[string]bool{
+	"name":          true,
+	"metadata.name": true,
+	"product":       true,
+}
+
+// The fields we have to read from the cluster.
+var statusFields = map[string]bool{
+	"status.current":           true,
+	"status.phase":             true,
+	"status.kubernetesVersion": true,
+	"status.error":             true,
+	"spec.minCPUs":             true,
 }
 
 type clusterFields api.Cluster
 
 func (cf *clusterFields) Has(field string) bool {
-	return field == "name" || field == "product"
+	return kubeconfigFields[field] || statusFields[field]
 }
 
 func (cf *clusterFields) Get(field string) string {
-	if field == "name" {
-		return (*api.Cluster)(cf).Name
-	}
-	if field == "product" {
-		return (*api.Cluster)(cf).Product
+	cluster := (*api.Cluster)(cf)
+	switch field {
+	case "name", "metadata.name":
+		return cluster.Name
+	case "product":
+		return cluster.Product
+	case "status.current":
+		return strconv.FormatBool(cluster.Status.Current)
+	case "status.phase":
+		return string(cluster.Status.Phase)
+	case "status.kubernetesVersion":
+		return cluster.Status.KubernetesVersion
+	case "status.error":
+		return cluster.Status.Error
+	case "spec.minCPUs":
+		return strconv.Itoa(cluster.MinCPUs)
 	}
 	return ""
 }
 
 var _ fields.Fields = &clusterFields{}
+
+// A parsed ListOptions.
+type clusterSelector struct {
+	fields fields.Selector
+	labels labels.Selector
+
+	// The subset of the field selector that we can check
+	// before we health-check the cluster.
+	kubeconfigFields fields.Selector
+}
+
+func newClusterSelector(options ListOptions) (*clusterSelector, error) {
+	fieldSelector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	labelSelector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfigSelectors := []fields.Selector{}
+	for _, r := range fieldSelector.Requirements() {
+		if !(&clusterFields{}).Has(r.Field) {
+			if isLabelsField(r.Field) {
+				return nil, fmt.Errorf("field label not supported: %s. Select clusters by label with -l, e.g., -l team=payments", r.Field)
+			}
+			return nil, fmt.Errorf("field label not supported: %s", r.Field)
+		}
+		if !kubeconfigFields[r.Field] {
+			continue
+		}
+		if r.Operator == selection.NotEquals {
+			kubeconfigSelectors = append(kubeconfigSelectors, fields.OneTermNotEqualSelector(r.Field, r.Value))
+		} else {
+			kubeconfigSelectors = append(kubeconfigSelectors, fields.OneTermEqualSelector(r.Field, r.Value))
+		}
+	}
+
+	return &clusterSelector{
+		fields:           fieldSelector,
+		labels:           labelSelector,
+		kubeconfigFields: fields.AndSelectors(kubeconfigSelectors...),
+	}, nil
+}
+
+// Users reach for field selectors like labels.team=payments
+// when they mean a label selector.
+func isLabelsField(field string) bool {
+	for _, prefix := range []string{"labels", "metadata.labels"} {
+		if field == prefix || strings.HasPrefix(field, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the cluster might match, judging only by what's in the kubeconfig.
+func (s *clusterSelector) mightMatch(cluster *api.Cluster) bool {
+	return s.kubeconfigFields.Matches((*clusterFields)(cluster))
+}
+
+// Whether a fully-populated cluster matches.
+func (s *clusterSelector) matches(cluster *api.Cluster) bool {
+	return s.fields.Matches((*clusterFields)(cluster)) &&
+		s.labels.Matches(labels.Set(cluster.Labels))
+}
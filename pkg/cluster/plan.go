@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// PlanAction is the action Apply will take to reconcile a cluster.
+type PlanAction string
+
+const (
+	PlanActionCreate   PlanAction = "create"
+	PlanActionRecreate PlanAction = "recreate"
+	PlanActionRestart  PlanAction = "restart"
+	PlanActionUpdate   PlanAction = "update"
+	PlanActionNoOp     PlanAction = "no-op"
+)
+
+// Plan describes what Apply would do to a cluster, without doing it.
+type Plan struct {
+	// The cluster name, after defaults have been filled in.
+	Name string `json:"name"`
+
+	Product string `json:"product"`
+
+	Action PlanAction `json:"action"`
+
+	// A human-readable explanation of why we chose this action.
+	Reason string `json:"reason,omitempty"`
+
+	// The diff between the current and desired config, when that's
+	// what triggered a recreate or an update.
+	Diff string `json:"diff,omitempty"`
+}
+
+// Whether applying this plan would change the cluster.
+func (p *Plan) HasChanges() bool {
+	return p.Action != PlanActionNoOp
+}
+
+// Compare the desired cluster against the existing cluster, and
+// report how Apply would reconcile them.
+//
+// Plan never modifies the cluster or the machine it runs on. In particular,
+// it won't start the machine, so a cluster on a stopped machine may look like
+// it needs to be created.
+func (c *Controller) Plan(ctx context.Context, desired *api.Cluster) (*Plan, error) {
+	err := validate(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	desired = desired.DeepCopy()
+	FillDefaults(desired)
+
+	plan := &Plan{
+		Name:    desired.Name,
+		Product: desired.Product,
+	}
+
+	existing, err := c.Get(ctx, desired.Name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		plan.Action = PlanActionCreate
+		plan.Reason = "cluster does not exist"
+		return plan, nil
+	}
+
+	reason, diff := c.recreateReason(ctx, desired, existing)
+	if reason != "" {
+		plan.Action = PlanActionRecreate
+		plan.Reason = reason
+		plan.Diff = diff
+		return plan, nil
+	}
+
+	if existing.Status.CreationTimestamp.Time.IsZero() {
+		plan.Action = PlanActionCreate
+		plan.Reason = "cluster is not running"
+		if existing.Status.Error != "" {
+			plan.Reason = fmt.Sprintf("cluster is not running: %s", existing.Status.Error)
+		}
+		return plan, nil
+	}
+
+	if existing.Status.CPUs < desired.MinCPUs {
+		plan.Action = PlanActionRestart
+		plan.Reason = fmt.Sprintf("desired minCPUs (%d) is more than current (%d)",
+			desired.MinCPUs, existing.Status.CPUs)
+		return plan, nil
+	}
+
+	if colimaMemoryChanged(desired, existing) {
+		existingMemory := 0
+		if existing.Colima != nil {
+			existingMemory = existing.Colima.Memory
+		}
+		plan.Action = PlanActionRestart
+		plan.Reason = fmt.Sprintf("desired colima memory (%dGiB) does not match current (%dGiB)",
+			desired.Colima.Memory, existingMemory)
+		return plan, nil
+	}
+
+	// Apply rewrites the recorded spec when only the labels change.
+	if !labels.Equals(desired.Labels, existing.Labels) {
+		plan.Action = PlanActionUpdate
+		plan.Reason = "desired labels do not match current"
+		plan.Diff = cmp.Diff(existing.Labels, desired.Labels)
+		return plan, nil
+	}
+
+	plan.Action = PlanActionNoOp
+	return plan, nil
+}
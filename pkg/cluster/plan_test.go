@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pseudonator/yap/pkg/api"
+	clusterid "github.com/pseudonator/yap/pkg/internal/cluster"
+)
+
+func TestPlanCreate(t *testing.T) {
+	f := newFixture(t)
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	plan, err := f.controller.Plan(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &Plan{
+		Name:    "kind-kind",
+		Product: "kind",
+		Action:  PlanActionCreate,
+		Reason:  "cluster does not exist",
+	}, plan)
+	assert.True(t, plan.HasChanges())
+	assert.Nil(t, kindAdmin.created)
+	assert.Equal(t, false, f.d4m.started)
+}
+
+func TestPlanNoOp(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	_ = f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+
+	plan, err := f.controller.Plan(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PlanActionNoOp, plan.Action)
+	assert.False(t, plan.HasChanges())
+}
+
+func TestPlanRestart(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	_ = f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+
+	plan, err := f.controller.Plan(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		MinCPUs: 8,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PlanActionRestart, plan.Action)
+	assert.Equal(t, "desired minCPUs (8) is more than current (1)", plan.Reason)
+}
+
+func TestPlanUpdateLabels(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	_ = f.newFakeAdmin(clusterid.ProductKIND)
+	f.apply(clusterid.ProductKIND, 0)
+
+	plan, err := f.controller.Plan(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+		Labels:  map[string]string{"team": "dev"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PlanActionUpdate, plan.Action)
+	assert.Equal(t, "desired labels do not match current", plan.Reason)
+	assert.Contains(t, plan.Diff, `"team": "dev"`)
+	assert.True(t, plan.HasChanges())
+}
+
+func TestPlanRecreate(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	minikubeAdmin := f.newFakeAdmin(clusterid.ProductMinikube)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductMinikube),
+		Minikube: &api.MinikubeCluster{ContainerRuntime: "docker"},
+	})
+	require.NoError(t, err)
+	minikubeAdmin.created = nil
+
+	plan, err := f.controller.Plan(context.Background(), &api.Cluster{
+		Product:  string(clusterid.ProductMinikube),
+		Minikube: &api.MinikubeCluster{ContainerRuntime: "containerd"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PlanActionRecreate, plan.Action)
+	assert.Equal(t, "desired Minikube config does not match current", plan.Reason)
+	assert.Contains(t, plan.Diff, `ContainerRuntime: "docker"`)
+	assert.Contains(t, plan.Diff, `ContainerRuntime: "containerd"`)
+
+	// Planning must not touch the cluster.
+	assert.Nil(t, minikubeAdmin.created)
+	assert.Nil(t, minikubeAdmin.deleted)
+}
+
+func TestPlanInvalid(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.controller.Plan(context.Background(), &api.Cluster{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "product field must be non-empty")
+	}
+}
@@ -0,0 +1,181 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// How often WaitForCondition re-checks the cluster.
+const waitForConditionInterval = time.Second
+
+// Both the GA and beta annotations are still in use.
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// ParseConditionType matches a condition name case-insensitively,
+// so that `--for=condition=ready` works.
+func ParseConditionType(s string) (api.ClusterConditionType, error) {
+	all := append([]api.ClusterConditionType{api.ClusterConditionReady}, conditionOrder...)
+	names := []string{}
+	for _, t := range all {
+		if strings.EqualFold(string(t), s) {
+			return t, nil
+		}
+		names = append(names, string(t))
+	}
+	return "", fmt.Errorf("unknown condition %q. Valid conditions: %s", s, strings.Join(names, ", "))
+}
+
+// WaitForCondition polls the cluster until the given condition is True,
+// and returns the last observed cluster.
+//
+// Returns immediately if the cluster doesn't exist.
+func (c *Controller) WaitForCondition(ctx context.Context, name string, t api.ClusterConditionType, timeout time.Duration) (*api.Cluster, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last *api.Cluster
+	var lastErr error
+	err := wait.PollImmediateUntilWithContext(ctx, waitForConditionInterval, func(ctx context.Context) (bool, error) {
+		err := c.refreshConfigs()
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+
+		cluster, err := c.Get(ctx, name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, err
+			}
+			lastErr = err
+			return false, nil
+		}
+
+		if t == api.ClusterConditionReady {
+			setCondition(&cluster.Status, c.readyCondition(ctx, cluster))
+		}
+
+		last = cluster
+		condition := GetCondition(cluster.Status, t)
+		return condition != nil && condition.Status == metav1.ConditionTrue, nil
+	})
+	if err == nil {
+		return last, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if last != nil {
+		condition := GetCondition(last.Status, t)
+		if condition != nil {
+			return last, fmt.Errorf("timed out waiting for cluster %s to be %s. Last status: %s (%s) %s",
+				name, t, condition.Status, condition.Reason, condition.Message)
+		}
+		return last, fmt.Errorf("timed out waiting for cluster %s to be %s: %s",
+			name, t, last.Status.Error)
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("timed out waiting for cluster %s to be %s: %v", name, t, lastErr)
+	}
+	return nil, fmt.Errorf("timed out waiting for cluster %s to be %s", name, t)
+}
+
+// Checks whether the cluster can run workloads, building on
+// the conditions that populateCluster already reported.
+func (c *Controller) readyCondition(ctx context.Context, cluster *api.Cluster) api.ClusterCondition {
+	notReady := func(reason, message string) api.ClusterCondition {
+		return newCondition(api.ClusterConditionReady, metav1.ConditionFalse, reason, message)
+	}
+
+	for _, t := range []api.ClusterConditionType{api.ClusterConditionAPIServerReachable, api.ClusterConditionNodesReady} {
+		condition := GetCondition(cluster.Status, t)
+		if condition == nil {
+			return notReady(fmt.Sprintf("%sUnknown", t), fmt.Sprintf("%s is unknown: %s", t, cluster.Status.Error))
+		}
+		if condition.Status != metav1.ConditionTrue {
+			return notReady(condition.Reason, condition.Message)
+		}
+	}
+
+	client, err := c.client(cluster.Name)
+	if err != nil {
+		return notReady("ClientConfigInvalid", err.Error())
+	}
+
+	checks := []struct {
+		reason string
+		check  func(ctx context.Context, client kubernetes.Interface) error
+	}{
+		{"DNSUnavailable", checkDNSAvailable},
+		{"NoDefaultStorageClass", checkDefaultStorageClass},
+		{"NoDefaultServiceAccount", checkDefaultServiceAccount},
+	}
+	for _, check := range checks {
+		err := check.check(ctx, client)
+		if err != nil {
+			return notReady(check.reason, err.Error())
+		}
+	}
+
+	return newCondition(api.ClusterConditionReady, metav1.ConditionTrue, "ClusterReady", "")
+}
+
+// CoreDNS and kube-dns both use the k8s-app=kube-dns label.
+func checkDNSAvailable(ctx context.Context, client kubernetes.Interface) error {
+	deployments, err := client.AppsV1().Deployments("kube-system").List(ctx, metav1.ListOptions{
+		LabelSelector: "k8s-app=kube-dns",
+	})
+	if err != nil {
+		return fmt.Errorf("reading DNS deployments: %v", err)
+	}
+	if len(deployments.Items) == 0 {
+		return fmt.Errorf("no DNS deployment found in kube-system")
+	}
+	for _, d := range deployments.Items {
+		if d.Status.AvailableReplicas > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("DNS deployment %s has no available replicas", deployments.Items[0].Name)
+}
+
+func checkDefaultStorageClass(ctx context.Context, client kubernetes.Interface) error {
+	classes, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("reading storage classes: %v", err)
+	}
+	for _, sc := range classes.Items {
+		for _, annotation := range defaultStorageClassAnnotations {
+			if sc.Annotations[annotation] == "true" {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no default StorageClass found")
+}
+
+// The ServiceAccount controller creates the default ServiceAccount
+// asynchronously, and pods can't be created until it exists.
+func checkDefaultServiceAccount(ctx context.Context, client kubernetes.Interface) error {
+	_, err := client.CoreV1().ServiceAccounts("default").Get(ctx, "default", metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("default ServiceAccount not created yet")
+		}
+		return fmt.Errorf("reading default ServiceAccount: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+func TestWaitForReady(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	_, err := f.controller.WaitForCondition(ctx, "microk8s", api.ClusterConditionReady, 10*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(),
+			"timed out waiting for cluster microk8s to be Ready. Last status: False (NodesNotReady) 0/1 nodes ready")
+	}
+
+	f.markNodeReady("node-1")
+	_, err = f.controller.WaitForCondition(ctx, "microk8s", api.ClusterConditionReady, 10*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "(DNSUnavailable) no DNS deployment found in kube-system")
+	}
+
+	_, err = f.fakeK8s.AppsV1().Deployments("kube-system").Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "coredns", Labels: map[string]string{"k8s-app": "kube-dns"}},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = f.controller.WaitForCondition(ctx, "microk8s", api.ClusterConditionReady, 10*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "(NoDefaultStorageClass) no default StorageClass found")
+	}
+
+	_, err = f.fakeK8s.StorageV1().StorageClasses().Create(ctx, &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = f.controller.WaitForCondition(ctx, "microk8s", api.ClusterConditionReady, 10*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "(NoDefaultServiceAccount) default ServiceAccount not created yet")
+	}
+
+	_, err = f.fakeK8s.CoreV1().ServiceAccounts("default").Create(ctx, &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	cluster, err := f.controller.WaitForCondition(ctx, "microk8s", api.ClusterConditionReady, time.Second)
+	require.NoError(t, err)
+	condition := GetCondition(cluster.Status, api.ClusterConditionReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, "ClusterReady", condition.Reason)
+}
+
+func TestWaitForConditionNotFound(t *testing.T) {
+	c := newFakeController(t)
+	_, err := c.WaitForCondition(context.Background(), "kind-kind", api.ClusterConditionNodesReady, time.Second)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestParseConditionType(t *testing.T) {
+	ct, err := ParseConditionType("ready")
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterConditionReady, ct)
+
+	ct, err = ParseConditionType("NodesReady")
+	require.NoError(t, err)
+	assert.Equal(t, api.ClusterConditionNodesReady, ct)
+
+	_, err = ParseConditionType("Happy")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown condition "Happy". Valid conditions: Ready, APIServerReachable`)
+	}
+}
+
+func (f *fixture) markNodeReady(name string) {
+	node, err := f.fakeK8s.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(f.t, err)
+	node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	_, err = f.fakeK8s.CoreV1().Nodes().UpdateStatus(context.Background(), node, metav1.UpdateOptions{})
+	require.NoError(f.t, err)
+}
@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// Namespaces that cluster products create for their own components.
+// Pods in these namespaces don't count as user workloads.
+var systemNamespaces = map[string]bool{
+	"kube-system":        true,
+	"kube-public":        true,
+	"kube-node-lease":    true,
+	"local-path-storage": true,
+	"metallb-system":     true,
+}
+
+// RecreateConfirmer decides whether Apply may delete a cluster that's
+// running workloads, so that it can recreate it.
+//
+// Receives the existing cluster, the reason it needs to be recreated,
+// and the workloads running on it, as namespace/name pairs. Workloads is
+// nil if we couldn't list them, so the cluster may be running anything.
+type RecreateConfirmer func(cluster *api.Cluster, reason string, workloads []string) (bool, error)
+
+// RecreateRefusedError is returned by Apply when the cluster needs to be
+// recreated, but deleting it wasn't confirmed.
+type RecreateRefusedError struct {
+	Name      string
+	Reason    string
+	Workloads []string
+
+	// Why we couldn't list the workloads, if we couldn't.
+	ListErr error
+}
+
+func (e *RecreateRefusedError) Error() string {
+	if e.ListErr != nil {
+		return fmt.Sprintf("refusing to delete cluster %s, because its workloads couldn't be listed: %v. "+
+			"It must be recreated because %s. Use --force to delete it anyway",
+			e.Name, e.ListErr, e.Reason)
+	}
+	return fmt.Sprintf("refusing to delete cluster %s, which is running %d workloads. "+
+		"It must be recreated because %s. Use --force to delete it anyway",
+		e.Name, len(e.Workloads), e.Reason)
+}
+
+// IsRecreateRefused checks whether Apply refused to delete a cluster.
+func IsRecreateRefused(err error) bool {
+	var refused *RecreateRefusedError
+	return errors.As(err, &refused)
+}
+
+// Sets the function that Apply uses to confirm that it's OK to delete a
+// cluster with workloads. When not set, Apply deletes clusters without asking.
+func (c *Controller) SetRecreateConfirmer(confirm RecreateConfirmer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.confirmRecreate = confirm
+}
+
+func (c *Controller) recreateConfirmer() RecreateConfirmer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.confirmRecreate
+}
+
+// Make sure we're allowed to delete the existing cluster.
+func (c *Controller) confirmDelete(ctx context.Context, existing *api.Cluster, reason string) error {
+	confirm := c.recreateConfirmer()
+	if confirm == nil {
+		return nil
+	}
+
+	// If we can't list the workloads, we can't tell that it's safe
+	// to delete the cluster, so we ask anyway.
+	workloads, listErr := c.workloads(ctx, existing)
+	if listErr == nil && len(workloads) == 0 {
+		return nil
+	}
+	if listErr != nil {
+		klog.V(4).Infof("WARNING: listing cluster %s workloads: %v\n", existing.Name, listErr)
+		workloads = nil
+	}
+
+	ok, err := confirm(existing, reason, workloads)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &RecreateRefusedError{Name: existing.Name, Reason: reason, Workloads: workloads, ListErr: listErr}
+	}
+	return nil
+}
+
+// Lists the pods running outside of system namespaces.
+func (c *Controller) workloads(ctx context.Context, cluster *api.Cluster) ([]string, error) {
+	client, err := c.client(cluster.Name)
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %v", err)
+	}
+
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, pod := range pods.Items {
+		if systemNamespaces[pod.Namespace] {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+	}
+	return result, nil
+}
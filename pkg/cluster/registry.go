@@ -0,0 +1,150 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/pseudonator/yap/pkg/api"
+	clusterid "github.com/pseudonator/yap/pkg/internal/cluster"
+	"github.com/pseudonator/yap/pkg/registry"
+)
+
+// The ConfigMap where clusters advertise their local registry,
+// as described in KEP-1755.
+const localRegistryHostingConfigMap = "local-registry-hosting"
+const localRegistryHostingKey = "localRegistryHosting.v1"
+
+type registryController interface {
+	Apply(ctx context.Context, r *api.Registry) (*api.Registry, error)
+	Get(ctx context.Context, name string) (*api.Registry, error)
+}
+
+func supportsRegistry(product clusterid.Product) bool {
+	return product == clusterid.ProductKIND ||
+		product == clusterid.ProductK3D ||
+		product == clusterid.ProductMinikube
+}
+
+func (c *Controller) registryController(ctx context.Context) (registryController, error) {
+	dockerClient, err := c.getDockerClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.registryCtl == nil {
+		c.registryCtl = registry.NewController(c.iostreams, dockerClient)
+	}
+	return c.registryCtl, nil
+}
+
+// EnsureRegistry makes sure the named registry is running, creating it
+// if necessary.
+//
+// Callers that apply clusters concurrently use this to create shared
+// registries once, before the clusters race to create them.
+func (c *Controller) EnsureRegistry(ctx context.Context, name string) (*api.Registry, error) {
+	return c.ensureRegistryExists(ctx, name)
+}
+
+// Make sure the named registry is running, creating it if necessary.
+//
+// If the registry already exists, we try to preserve its port and
+// listen address, so that clusters already connected to it keep working.
+func (c *Controller) ensureRegistryExists(ctx context.Context, name string) (*api.Registry, error) {
+	regCtl, err := c.registryController(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := regCtl.Get(ctx, name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	desired := &api.Registry{
+		TypeMeta: registry.TypeMeta(),
+		Name:     name,
+	}
+	if existing != nil {
+		if existing.Status.State == "running" {
+			return existing, nil
+		}
+		desired.Port = existing.Status.HostPort
+		desired.ListenAddress = existing.Status.ListenAddress
+	}
+
+	return regCtl.Apply(ctx, desired)
+}
+
+// Publishes the local registry in the cluster, so that other tools can find it.
+func (c *Controller) writeRegistryHosting(ctx context.Context, admin Admin, cluster *api.Cluster, reg *api.Registry) error {
+	hosting, err := admin.LocalRegistryHosting(ctx, cluster, reg)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client(cluster.Name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(hosting)
+	if err != nil {
+		return err
+	}
+
+	err = client.CoreV1().ConfigMaps("kube-public").Delete(ctx, localRegistryHostingConfigMap, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	_, err = client.CoreV1().ConfigMaps("kube-public").Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      localRegistryHostingConfigMap,
+			Namespace: "kube-public",
+		},
+		Data: map[string]string{localRegistryHostingKey: string(data)},
+	}, metav1.CreateOptions{})
+	return err
+}
+
+func (c *Controller) populateLocalRegistryHosting(ctx context.Context, cluster *api.Cluster, client kubernetes.Interface) error {
+	cMap, err := client.CoreV1().ConfigMaps("kube-public").Get(ctx, localRegistryHostingConfigMap, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			return nil
+		}
+		return err
+	}
+
+	hosting := api.LocalRegistryHostingV1{}
+	err = yaml.Unmarshal([]byte(cMap.Data[localRegistryHostingKey]), &hosting)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %v", localRegistryHostingConfigMap, err)
+	}
+
+	if hosting.Host == "" {
+		return nil
+	}
+	cluster.Status.LocalRegistryHosting = &hosting
+	return nil
+}
+
+// Checks whether the registry container is already attached to the given network.
+func inNetwork(reg *api.Registry, network string) bool {
+	for _, n := range reg.Status.Networks {
+		if n == network {
+			return true
+		}
+	}
+	return false
+}
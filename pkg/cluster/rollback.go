@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// How long we give a rollback to clean up.
+//
+// The rollback can't use the apply context, because that's usually
+// the context that was canceled.
+const rollbackTimeout = 2 * time.Minute
+
+// Returned when we stop waiting for a create or delete that can't be
+// interrupted. The cluster may still be changing, so it's not safe to
+// roll back.
+var errStillRunning = errors.New("still running")
+
+// Sets whether Apply deletes a cluster that it was creating when creation
+// fails or is interrupted. When not set, Apply leaves the half-created
+// cluster behind, so that users can debug it.
+func (c *Controller) SetRollbackOnFailure(rollback bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollbackOnFailure = rollback
+}
+
+func (c *Controller) shouldRollbackOnFailure() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rollbackOnFailure
+}
+
+// Deletes a cluster that failed to create, and removes its kubectl context.
+//
+// Only call this once the admin's Create has returned, so that the delete
+// can't race a create that's still in flight.
+func (c *Controller) rollbackCreate(admin Admin, desired *api.Cluster, cause error) error {
+	if errors.Is(cause, errStillRunning) {
+		return fmt.Errorf("cluster %s is still being created, so it can't be rolled back. "+
+			"Delete it once creation finishes", desired.Name)
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Creating cluster %q failed: %v\nRolling back...\n", desired.Name, cause)
+	if errors.Is(cause, context.Canceled) {
+		_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Press Ctrl-C again to abort the rollback\n")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	err := admin.Delete(ctx, desired)
+	if err != nil {
+		return errors.Wrapf(err, "deleting cluster %s", desired.Name)
+	}
+
+	err = c.reloadConfigs()
+	if err != nil {
+		return err
+	}
+
+	// Some products leave the context behind when deleting
+	// a cluster that never finished creating.
+	_, ok := c.configCopy().Contexts[desired.Name]
+	if ok {
+		err = c.configWriter.DeleteContext(desired.Name)
+		if err != nil {
+			return errors.Wrapf(err, "deleting context %s", desired.Name)
+		}
+	}
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Rolled back cluster %q\n", desired.Name)
+	return nil
+}
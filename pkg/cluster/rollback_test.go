@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/pseudonator/yap/pkg/api"
+	clusterid "github.com/pseudonator/yap/pkg/internal/cluster"
+)
+
+func TestClusterApplyRollbackOnFailure(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.failHealthCheck()
+
+	f.controller.SetRollbackOnFailure(true)
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out waiting for cluster to start")
+	}
+
+	require.NotNil(t, kindAdmin.deleted)
+	assert.Equal(t, "kind-kind", kindAdmin.deleted.Name)
+	_, ok := f.config.Contexts["kind-kind"]
+	assert.False(t, ok)
+	assert.Contains(t, f.errOut.String(), "Rolled back cluster \"kind-kind\"")
+}
+
+func TestClusterApplyRollbackWaitsForCreate(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	library := f.newKindLibrary(time.Minute)
+
+	f.controller.SetRollbackOnFailure(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	applied := make(chan error, 1)
+	go func() {
+		_, err := f.controller.Apply(ctx, &api.Cluster{Product: string(clusterid.ProductKIND)})
+		applied <- err
+	}()
+	<-library.started
+	cancel()
+
+	// The rollback waits for kind to finish creating.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"create kind"}, library.getCalls())
+
+	close(library.release)
+	err := <-applied
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context canceled")
+	}
+	assert.Equal(t, []string{"create kind", "created kind", "delete kind"}, library.getCalls())
+	assert.Contains(t, f.errOut.String(), "Press Ctrl-C again to abort the rollback")
+	assert.Contains(t, f.errOut.String(), "Rolled back cluster \"kind-kind\"")
+}
+
+func TestClusterApplyNoRollbackWhileCreating(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	library := f.newKindLibrary(10 * time.Millisecond)
+	defer close(library.release)
+
+	f.controller.SetRollbackOnFailure(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := f.controller.Apply(ctx, &api.Cluster{Product: string(clusterid.ProductKIND)})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Rollback failed: cluster kind-kind is still being created, so it can't be rolled back")
+	}
+	assert.Equal(t, []string{"create kind"}, library.getCalls())
+}
+
+func TestClusterApplyNoRollbackOfExistingCluster(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	require.NoError(t, err)
+
+	// The cluster exists, but it's stopped or unreachable,
+	// so Apply tries to start it again.
+	f.failHealthCheck()
+	f.fakeK8s.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	f.controller.SetRollbackOnFailure(true)
+	_, err = f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	assert.Error(t, err)
+
+	assert.Nil(t, kindAdmin.deleted)
+	_, ok := f.config.Contexts["kind-kind"]
+	assert.True(t, ok)
+	assert.NotContains(t, f.errOut.String(), "Rolling back")
+}
+
+func TestClusterApplyNoRollbackByDefault(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+	f.failHealthCheck()
+
+	_, err := f.controller.Apply(context.Background(), &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	assert.Error(t, err)
+
+	// Leave the cluster behind for debugging.
+	assert.Nil(t, kindAdmin.deleted)
+	_, ok := f.config.Contexts["kind-kind"]
+	assert.True(t, ok)
+}
+
+func TestClusterApplyRollbackOnInterrupt(t *testing.T) {
+	f := newFixture(t)
+	f.setOS("darwin")
+	kindAdmin := f.newFakeAdmin(clusterid.ProductKIND)
+
+	// Simulate Ctrl-C while we wait for the cluster to start.
+	ctx, cancel := context.WithCancel(context.Background())
+	f.controller.waitForClusterCreateTimeout = waitForClusterCreateTimeout
+	f.fakeK8s.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		cancel()
+		return true, nil, fmt.Errorf("apiserver is starting")
+	})
+
+	f.controller.SetRollbackOnFailure(true)
+	_, err := f.controller.Apply(ctx, &api.Cluster{
+		Product: string(clusterid.ProductKIND),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context canceled")
+	}
+	require.NotNil(t, kindAdmin.deleted)
+	_, ok := f.config.Contexts["kind-kind"]
+	assert.False(t, ok)
+}
+
+// Makes the apiserver look like it never finishes starting.
+func (f *fixture) failHealthCheck() {
+	f.fakeK8s.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("apiserver is starting")
+	})
+}
+
+// Uses the kind admin, with a kind library whose Create blocks until released.
+func (f *fixture) newKindLibrary(gracePeriod time.Duration) *fakeKindLibrary {
+	library := newFakeKindLibrary()
+	admin := newKindAdmin(f.controller.iostreams, f.dockerClient)
+	admin.provider = &kindLibraryProvider{library: library, errOut: io.Discard, gracePeriod: gracePeriod}
+	f.controller.admins[clusterid.ProductKIND] = admin
+	return library
+}
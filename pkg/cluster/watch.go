@@ -0,0 +1,173 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// How often Watch re-reads the kubeconfig and health-checks clusters.
+const defaultWatchInterval = 2 * time.Second
+
+// Watch polls the clusters in the kubeconfig and sends an event whenever a
+// cluster appears, disappears, or changes in a way users care about
+// (health, phase, conditions, current context, Kubernetes version, or CPUs).
+//
+// The first poll sends an Added event for every existing cluster.
+//
+// Clients are reused between polls unless the kubeconfig changes,
+// so each poll only costs a health check per cluster.
+func (c *Controller) Watch(ctx context.Context, options ListOptions) (watch.Interface, error) {
+	_, err := newClusterSelector(options)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := c.watchInterval
+	if interval == 0 {
+		interval = defaultWatchInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	result := make(chan watch.Event)
+	w := watch.NewProxyWatcher(result)
+	go func() {
+		select {
+		case <-w.StopChan():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	go func() {
+		defer close(result)
+
+		send := func(e watch.Event) bool {
+			select {
+			case result <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		known := make(map[string]*api.Cluster)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for first := true; ; first = false {
+			if !first {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+
+				err := c.refreshConfigs()
+				if err != nil {
+					klog.V(4).Infof("WARNING: reloading kubeconfig: %v\n", err)
+				}
+			}
+
+			list, err := c.List(ctx, options)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				status := apierrors.NewInternalError(err).ErrStatus
+				if !send(watch.Event{Type: watch.Error, Object: &status}) {
+					return
+				}
+				continue
+			}
+
+			for _, e := range diffClusters(known, list.Items) {
+				if !send(e) {
+					return
+				}
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// Compares the last clusters we saw against the latest list, updates
+// the known clusters, and returns the events for the differences.
+func diffClusters(known map[string]*api.Cluster, latest []api.Cluster) []watch.Event {
+	events := []watch.Event{}
+	seen := make(map[string]bool, len(latest))
+	for i := range latest {
+		cluster := &latest[i]
+		seen[cluster.Name] = true
+
+		old, ok := known[cluster.Name]
+		known[cluster.Name] = cluster
+		if ok {
+			preserveTransitionTimes(&old.Status, &cluster.Status)
+		}
+
+		if !ok {
+			events = append(events, watch.Event{Type: watch.Added, Object: cluster})
+		} else if clusterChanged(old, cluster) {
+			events = append(events, watch.Event{Type: watch.Modified, Object: cluster})
+		}
+	}
+
+	// Send deletions in the same order as the list, for stable output.
+	for _, name := range sortedClusterNames(known) {
+		if seen[name] {
+			continue
+		}
+		events = append(events, watch.Event{Type: watch.Deleted, Object: known[name]})
+		delete(known, name)
+	}
+	return events
+}
+
+// Error messages often vary from one health check to the next (timeouts
+// vs refused connections), so we only compare whether the cluster is healthy.
+func clusterChanged(old, cluster *api.Cluster) bool {
+	return old.Product != cluster.Product ||
+		(old.Status.Error == "") != (cluster.Status.Error == "") ||
+		old.Status.Current != cluster.Status.Current ||
+		old.Status.Phase != cluster.Status.Phase ||
+		old.Status.KubernetesVersion != cluster.Status.KubernetesVersion ||
+		old.Status.CPUs != cluster.Status.CPUs ||
+		conditionsChanged(old.Status, cluster.Status)
+}
+
+func sortedClusterNames(clusters map[string]*api.Cluster) []string {
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Like reloadConfigs, but keeps the cached clients if the kubeconfig
+// hasn't changed.
+func (c *Controller) refreshConfigs() error {
+	config, err := c.configLoader()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reflect.DeepEqual(c.config, config) {
+		return nil
+	}
+	c.config = config
+	c.clients = make(map[string]kubernetes.Interface)
+	return nil
+}
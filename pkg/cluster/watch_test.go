@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/watch"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+func TestDiffClusters(t *testing.T) {
+	known := make(map[string]*api.Cluster)
+	events := diffClusters(known, []api.Cluster{
+		{Name: "kind-kind", Status: api.ClusterStatus{KubernetesVersion: "v1.24.0", CPUs: 4}},
+		{Name: "microk8s"},
+	})
+	assert.Equal(t, []string{"ADDED kind-kind", "ADDED microk8s"}, eventSummaries(events))
+
+	// Different error messages for an unhealthy cluster aren't a change.
+	events = diffClusters(known, []api.Cluster{
+		{Name: "kind-kind", Status: api.ClusterStatus{KubernetesVersion: "v1.24.0", CPUs: 4}},
+		{Name: "microk8s", Status: api.ClusterStatus{Error: "healthcheck: timeout"}},
+	})
+	assert.Equal(t, []string{"MODIFIED microk8s"}, eventSummaries(events))
+
+	events = diffClusters(known, []api.Cluster{
+		{Name: "kind-kind", Status: api.ClusterStatus{KubernetesVersion: "v1.24.0", CPUs: 4}},
+		{Name: "microk8s", Status: api.ClusterStatus{Error: "healthcheck: connection refused"}},
+	})
+	assert.Equal(t, []string{}, eventSummaries(events))
+
+	events = diffClusters(known, []api.Cluster{
+		{Name: "kind-kind", Status: api.ClusterStatus{KubernetesVersion: "v1.24.0", CPUs: 8}},
+	})
+	assert.Equal(t, []string{"MODIFIED kind-kind", "DELETED microk8s"}, eventSummaries(events))
+	assert.Equal(t, 1, len(known))
+}
+
+func TestClusterWatch(t *testing.T) {
+	f := newFixture(t)
+	f.controller.watchInterval = time.Millisecond
+
+	// The watcher reloads the config in the background, so guard our edits.
+	var mu sync.Mutex
+	config := f.config.DeepCopy()
+	f.controller.configLoader = func() (clientcmdapi.Config, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return *config.DeepCopy(), nil
+	}
+	editConfig := func(edit func(config *clientcmdapi.Config)) {
+		mu.Lock()
+		defer mu.Unlock()
+		edit(config)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w, err := f.controller.Watch(ctx, ListOptions{})
+	require.NoError(t, err)
+	defer w.Stop()
+
+	assert.Equal(t, "ADDED docker-desktop", nextEvent(t, w))
+	assert.Equal(t, "ADDED microk8s", nextEvent(t, w))
+
+	editConfig(func(config *clientcmdapi.Config) {
+		config.Contexts["kind-kind"] = &clientcmdapi.Context{Cluster: "kind-kind"}
+		config.Clusters["kind-kind"] = &clientcmdapi.Cluster{Server: "https://127.0.0.1:51234"}
+	})
+	assert.Equal(t, "ADDED kind-kind", nextEvent(t, w))
+
+	editConfig(func(config *clientcmdapi.Config) {
+		delete(config.Contexts, "kind-kind")
+	})
+	assert.Equal(t, "DELETED kind-kind", nextEvent(t, w))
+}
+
+func TestClusterWatchBadSelector(t *testing.T) {
+	c := newFakeController(t)
+	_, err := c.Watch(context.Background(), ListOptions{FieldSelector: "product"})
+	assert.Error(t, err)
+}
+
+func nextEvent(t *testing.T, w watch.Interface) string {
+	select {
+	case e, ok := <-w.ResultChan():
+		require.True(t, ok, "watch closed unexpectedly")
+		return eventSummaries([]watch.Event{e})[0]
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch event")
+		return ""
+	}
+}
+
+func eventSummaries(events []watch.Event) []string {
+	result := []string{}
+	for _, e := range events {
+		result = append(result, string(e.Type)+" "+e.Object.(*api.Cluster).Name)
+	}
+	return result
+}
@@ -1,24 +1,53 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
 
 	"github.com/pseudonator/yap/pkg/api"
 	"github.com/pseudonator/yap/pkg/cluster"
-	"github.com/pseudonator/yap/pkg/visitor"
+	"github.com/pseudonator/yap/pkg/registry"
 )
 
 type ApplyOptions struct {
 	*genericclioptions.PrintFlags
 	*genericclioptions.FileNameFlags
+	ManifestFlags
 	genericclioptions.IOStreams
 
 	Filenames []string
+	Recursive bool
+
+	// When set, print what apply would do instead of doing it.
+	DryRun bool
+
+	// When set, delete and recreate clusters without asking,
+	// even if they're running workloads.
+	Force bool
+
+	// When set, delete clusters that fail to create, or that
+	// are interrupted while creating.
+	RollbackOnFailure bool
+
+	clusterPlanner        clusterPlanner
+	clusterApplierFactory func(streams genericclioptions.IOStreams) (clusterApplier, error)
+
+	// Prompts from concurrent applies take turns reading stdin.
+	promptMu sync.Mutex
+	in       *bufio.Reader
+
+	// Guards the output of concurrent applies. Prompts hold it until
+	// they're answered, so other clusters' output can't interrupt them.
+	outMu sync.Mutex
 }
 
 func NewApplyOptions() *ApplyOptions {
@@ -26,7 +55,7 @@ func NewApplyOptions() *ApplyOptions {
 		PrintFlags: genericclioptions.NewPrintFlags("created"),
 		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
 	}
-	o.FileNameFlags = &genericclioptions.FileNameFlags{Filenames: &o.Filenames}
+	o.FileNameFlags = &genericclioptions.FileNameFlags{Filenames: &o.Filenames, Recursive: &o.Recursive}
 	return o
 }
 
@@ -35,14 +64,28 @@ func (o *ApplyOptions) Command() *cobra.Command {
 		Use:   "apply -f FILENAME",
 		Short: "Apply a cluster config to the currently running clusters",
 		Example: "  yap apply -f cluster.yaml\n" +
-			"  cat cluster.yaml | yap apply -f -",
+			"  cat cluster.yaml | yap apply -f -\n" +
+			"  yap apply -f clusters/ -R\n" +
+			"  yap apply -f 'clusters/*.yaml'\n" +
+			"  yap apply -f cluster.yaml --dry-run\n" +
+			"  yap apply -f cluster.yaml --force\n" +
+			"  yap apply -f cluster.yaml --rollback-on-failure\n" +
+			"  REGISTRY_PORT=5001 yap apply -f cluster.yaml --expand-env\n" +
+			"  yap apply -f cluster.yaml --values dev.yaml",
 		Run: o.Run,
 	}
 
 	cmd.SetOut(o.Out)
 	cmd.SetErr(o.ErrOut)
 	o.FileNameFlags.AddFlags(cmd.Flags())
+	o.ManifestFlags.AddFlags(cmd.Flags())
 	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun,
+		"If true, print what apply would change without changing anything. Same as 'yap diff'.")
+	cmd.Flags().BoolVar(&o.Force, "force", o.Force,
+		"If true, delete and recreate clusters that don't match the config without asking, even if they're running workloads.")
+	cmd.Flags().BoolVar(&o.RollbackOnFailure, "rollback-on-failure", o.RollbackOnFailure,
+		"If true, delete clusters that fail to create or are interrupted while creating, and remove their kubectl contexts.")
 
 	return cmd
 }
@@ -53,43 +96,66 @@ func (o *ApplyOptions) Run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	err := o.run()
+	ctx, cancel := signalContext()
+	err := o.run(ctx)
+	cancel()
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
 		os.Exit(1)
 	}
 }
 
-func (o *ApplyOptions) run() error {
-	ctx := context.TODO()
+func (o *ApplyOptions) run(ctx context.Context) error {
+	format := ""
+	if o.PrintFlags.OutputFormat != nil {
+		format = *o.PrintFlags.OutputFormat
+	}
+	if o.DryRun && format != "" && format != "json" {
+		return fmt.Errorf("unsupported output format: %s. Allowed formats: json", format)
+	}
 
-	printer, err := toPrinter(o.PrintFlags)
+	objects, err := o.ManifestFlags.decode(o.Filenames, o.Recursive, o.In)
 	if err != nil {
 		return err
 	}
 
-	visitors, err := visitor.FromStrings(o.Filenames, o.In)
-	if err != nil {
-		return err
+	if o.DryRun {
+		plans, err := planObjects(ctx, o.getClusterPlanner, objects, o.ErrOut)
+		if err != nil {
+			return err
+		}
+		return printPlans(o.Out, plans, format)
 	}
 
-	objects, err := visitor.DecodeAll(visitors)
+	printer, err := toPrinter(o.PrintFlags)
 	if err != nil {
 		return err
 	}
 
-	var cc *cluster.Controller
+	// Registries go first, so that clusters can connect to them.
+	registries := []*api.Registry{}
+	clusters := []*api.Cluster{}
 	for _, obj := range objects {
 		switch obj := obj.(type) {
+		case *api.Registry:
+			registries = append(registries, obj)
 		case *api.Cluster:
-			if cc == nil {
-				cc, err = cluster.DefaultController(o.IOStreams)
-				if err != nil {
-					return err
-				}
-			}
+			clusters = append(clusters, obj)
+		case *api.ClusterTemplate:
+			return templateNotApplicableError(obj)
+		default:
+			return fmt.Errorf("unrecognized type: %T", obj)
+		}
+	}
 
-			newObj, err := cc.Apply(ctx, obj)
+	if len(registries) > 0 {
+		rc, err := registry.DefaultController(o.IOStreams)
+		if err != nil {
+			return err
+		}
+
+		for _, obj := range registries {
+			newObj, err := rc.Apply(ctx, obj)
 			if err != nil {
 				return err
 			}
@@ -98,10 +164,213 @@ func (o *ApplyOptions) run() error {
 			if err != nil {
 				return err
 			}
+		}
+	}
 
-		default:
-			return fmt.Errorf("unrecognized type: %T", obj)
+	return o.applyClusters(ctx, clusters, printer)
+}
+
+type clusterApplier interface {
+	Apply(ctx context.Context, cluster *api.Cluster) (*api.Cluster, error)
+	SetRecreateConfirmer(confirm cluster.RecreateConfirmer)
+	SetRollbackOnFailure(rollback bool)
+	UseContext(name string) error
+	EnsureRegistry(ctx context.Context, name string) (*api.Registry, error)
+}
+
+// Applies the clusters concurrently.
+//
+// Each cluster gets its own controller, with its output prefixed by the
+// cluster name. Prints the clusters in the order they were given, and
+// reports every cluster that failed.
+func (o *ApplyOptions) applyClusters(ctx context.Context, clusters []*api.Cluster, printer printers.ResourcePrinter) error {
+	if len(clusters) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(clusters))
+	seen := make(map[string]bool, len(clusters))
+	for i, c := range clusters {
+		withDefaults := c.DeepCopy()
+		cluster.FillDefaults(withDefaults)
+		names[i] = withDefaults.Name
+		if seen[names[i]] {
+			return fmt.Errorf("cluster %s appears more than once", names[i])
+		}
+		seen[names[i]] = true
+	}
+
+	err := o.ensureSharedRegistries(ctx, clusters)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*api.Cluster, len(clusters))
+	errs := make([]error, len(clusters))
+	controllers := make([]clusterApplier, len(clusters))
+
+	var wg sync.WaitGroup
+	for i, c := range clusters {
+		streams := o.IOStreams
+		var flush func()
+		if len(clusters) > 1 {
+			out := newPrefixWriter(&o.outMu, o.IOStreams.Out, fmt.Sprintf("[%s] ", names[i]))
+			errOut := newPrefixWriter(&o.outMu, o.IOStreams.ErrOut, fmt.Sprintf("[%s] ", names[i]))
+			streams = genericclioptions.IOStreams{In: o.IOStreams.In, Out: out, ErrOut: errOut}
+			flush = func() {
+				_ = out.Flush()
+				_ = errOut.Flush()
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, c *api.Cluster) {
+			defer wg.Done()
+			if flush != nil {
+				defer flush()
+			}
+
+			cc, err := o.newClusterApplier(streams)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if !o.Force {
+				cc.SetRecreateConfirmer(o.confirmRecreate)
+			}
+			cc.SetRollbackOnFailure(o.RollbackOnFailure)
+			controllers[i] = cc
+			results[i], errs[i] = cc.Apply(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	failures := []string{}
+	for i, result := range results {
+		if errs[i] != nil {
+			failures = append(failures, fmt.Sprintf("  %s: %v", names[i], errs[i]))
+			continue
+		}
+
+		err := printer.PrintObj(result, o.Out)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(failures) > 0 {
+		if len(clusters) == 1 {
+			return errs[0]
+		}
+		return fmt.Errorf("%d of %d clusters failed to apply:\n%s",
+			len(failures), len(clusters), strings.Join(failures, "\n"))
+	}
+
+	// Each Apply switched the current context as it finished. Leave it on
+	// the last cluster in the config, so the result doesn't depend on timing.
+	if len(clusters) > 1 {
+		last := len(clusters) - 1
+		return controllers[last].UseContext(results[last].Name)
+	}
+	return nil
+}
+
+// Each cluster's controller creates its registry if it doesn't exist.
+// When clusters share a registry, the controllers would race to create
+// it, so we create those registries first, one at a time.
+func (o *ApplyOptions) ensureSharedRegistries(ctx context.Context, clusters []*api.Cluster) error {
+	count := make(map[string]int)
+	names := []string{}
+	for _, c := range clusters {
+		if c.Registry == "" {
+			continue
+		}
+		if count[c.Registry] == 0 {
+			names = append(names, c.Registry)
+		}
+		count[c.Registry]++
+	}
+
+	var cc clusterApplier
+	for _, name := range names {
+		if count[name] < 2 {
+			continue
+		}
+		if cc == nil {
+			var err error
+			cc, err = o.newClusterApplier(o.IOStreams)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := cc.EnsureRegistry(ctx, name)
+		if err != nil {
+			return fmt.Errorf("configuring registry %s: %v", name, err)
 		}
 	}
 	return nil
 }
+
+func (o *ApplyOptions) newClusterApplier(streams genericclioptions.IOStreams) (clusterApplier, error) {
+	if o.clusterApplierFactory != nil {
+		return o.clusterApplierFactory(streams)
+	}
+	return cluster.DefaultController(streams)
+}
+
+func (o *ApplyOptions) getClusterPlanner() (clusterPlanner, error) {
+	if o.clusterPlanner == nil {
+		controller, err := cluster.DefaultController(o.IOStreams)
+		if err != nil {
+			return nil, err
+		}
+		o.clusterPlanner = controller
+	}
+	return o.clusterPlanner, nil
+}
+
+// The most workloads we list when asking to delete a cluster.
+const maxConfirmWorkloads = 10
+
+// Asks the user whether to delete a cluster that's running workloads.
+//
+// Anything but an explicit yes, including a closed stdin, means no.
+// Other clusters' output waits until the prompt is answered.
+func (o *ApplyOptions) confirmRecreate(c *api.Cluster, reason string, workloads []string) (bool, error) {
+	o.promptMu.Lock()
+	defer o.promptMu.Unlock()
+	o.outMu.Lock()
+	defer o.outMu.Unlock()
+
+	_, _ = fmt.Fprintf(o.ErrOut, "Cluster %s must be deleted and recreated because %s.\n", c.Name, reason)
+	if workloads == nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "Its workloads couldn't be listed, so it may be running some.\n")
+	} else {
+		_, _ = fmt.Fprintf(o.ErrOut, "It is running %d workloads:\n", len(workloads))
+	}
+	for i, w := range workloads {
+		if i == maxConfirmWorkloads {
+			_, _ = fmt.Fprintf(o.ErrOut, "  ...and %d more\n", len(workloads)-maxConfirmWorkloads)
+			break
+		}
+		_, _ = fmt.Fprintf(o.ErrOut, "  %s\n", w)
+	}
+	_, _ = fmt.Fprintf(o.ErrOut, "Delete cluster %s? [y/N]: ", c.Name)
+
+	if o.in == nil {
+		o.in = bufio.NewReader(o.In)
+	}
+	line, err := o.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if err == io.EOF {
+		_, _ = fmt.Fprintln(o.ErrOut)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+func TestApplyConfirmRecreate(t *testing.T) {
+	streams, in, _, errOut := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	_, _ = in.Write([]byte("y\nno\n"))
+
+	c := &api.Cluster{Name: "kind-kind"}
+	ok, err := o.confirmRecreate(c, "desired Kind config does not match current", []string{"default/web"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, errOut.String(), "Cluster kind-kind must be deleted and recreated because desired Kind config does not match current.")
+	assert.Contains(t, errOut.String(), "  default/web\n")
+	assert.Contains(t, errOut.String(), "Delete cluster kind-kind? [y/N]: ")
+
+	ok, err = o.confirmRecreate(c, "reason", []string{"default/web"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Stdin is closed, so we shouldn't delete anything.
+	ok, err = o.confirmRecreate(c, "reason", []string{"default/web"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestApplyConfirmRecreateManyWorkloads(t *testing.T) {
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+
+	workloads := []string{}
+	for i := 0; i < 15; i++ {
+		workloads = append(workloads, fmt.Sprintf("default/web-%d", i))
+	}
+	ok, err := o.confirmRecreate(&api.Cluster{Name: "kind-kind"}, "reason", workloads)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, errOut.String(), "It is running 15 workloads:")
+	assert.Contains(t, errOut.String(), "  default/web-9\n")
+	assert.NotContains(t, errOut.String(), "default/web-10")
+	assert.Contains(t, errOut.String(), "  ...and 5 more\n")
+}
+
+func TestApplyConfirmRecreateWorkloadsUnknown(t *testing.T) {
+	streams, in, _, errOut := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	_, _ = in.Write([]byte("n\n"))
+
+	ok, err := o.confirmRecreate(&api.Cluster{Name: "kind-kind"}, "reason", nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, errOut.String(), "Its workloads couldn't be listed, so it may be running some.\n")
+	assert.NotContains(t, errOut.String(), "It is running")
+}
+
+func TestApplyConfirmRecreatePausesOutput(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	stdin, answer := io.Pipe()
+	errOut := &promptedWriter{prompted: make(chan struct{})}
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.In = stdin
+	o.ErrOut = errOut
+
+	confirmed := make(chan bool, 1)
+	go func() {
+		ok, _ := o.confirmRecreate(&api.Cluster{Name: "kind-kind"}, "reason", []string{"default/web"})
+		confirmed <- ok
+	}()
+	<-errOut.prompted
+
+	// Another cluster's output waits until the prompt is answered.
+	other := newPrefixWriter(&o.outMu, errOut, "[k3d-k3s-default] ")
+	written := make(chan struct{})
+	go func() {
+		_, _ = other.Write([]byte("Creating cluster\n"))
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("output written while waiting for an answer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, _ = answer.Write([]byte("y\n"))
+	assert.True(t, <-confirmed)
+	<-written
+	assert.True(t, strings.HasSuffix(errOut.String(), "Delete cluster kind-kind? [y/N]: [k3d-k3s-default] Creating cluster\n"))
+}
+
+// Signals when the recreate prompt has been written.
+type promptedWriter struct {
+	bytes.Buffer
+	prompted chan struct{}
+}
+
+func (w *promptedWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	if strings.HasSuffix(w.Buffer.String(), "[y/N]: ") {
+		close(w.prompted)
+	}
+	return n, err
+}
+
+const clusterListYAML = `apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterList
+items:
+- product: kind
+- product: k3d
+- product: minikube
+`
+
+func TestApplyClusterList(t *testing.T) {
+	streams, in, out, errOut := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	f := newFakeClusterAppliers()
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte(clusterListYAML))
+
+	err := o.run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind created\n"+
+		"cluster.yap.pseudonator.io/k3d-k3s-default created\n"+
+		"cluster.yap.pseudonator.io/minikube created\n", out.String())
+	assert.Contains(t, errOut.String(), "[kind-kind] Creating kind-kind\n")
+	assert.Contains(t, errOut.String(), "[minikube] Creating minikube\n")
+	assert.Equal(t, "minikube", f.currentContext)
+}
+
+func TestApplyClusterListErrors(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	f := newFakeClusterAppliers()
+	f.errors["kind-kind"] = fmt.Errorf("kind is broken")
+	f.errors["minikube"] = fmt.Errorf("minikube is broken")
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte(clusterListYAML))
+
+	err := o.run(context.Background())
+	if assert.Error(t, err) {
+		assert.Equal(t, "2 of 3 clusters failed to apply:\n"+
+			"  kind-kind: kind is broken\n"+
+			"  minikube: minikube is broken", err.Error())
+	}
+	assert.Equal(t, "cluster.yap.pseudonator.io/k3d-k3s-default created\n", out.String())
+	assert.Equal(t, "", f.currentContext)
+}
+
+func TestApplyClusterListDuplicate(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	f := newFakeClusterAppliers()
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte(clusterListYAML + "- product: kind\n"))
+
+	err := o.run(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cluster kind-kind appears more than once")
+	}
+	assert.Empty(t, f.applied)
+}
+
+func TestApplyRollbackOnFailure(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	f := newFakeClusterAppliers()
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte("apiVersion: yap.pseudonator.io/v1alpha1\nkind: Cluster\nproduct: kind\n"))
+
+	cmd := o.Command()
+	require.NoError(t, cmd.Flags().Set("rollback-on-failure", "true"))
+
+	err := o.run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, f.rollback)
+}
+
+func TestApplyClusterListSharedRegistry(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	f := newFakeClusterAppliers()
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte(`apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterList
+items:
+- product: kind
+  registry: shared-registry
+- product: k3d
+  registry: shared-registry
+- product: minikube
+  registry: minikube-registry
+`))
+
+	err := o.run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind created\n"+
+		"cluster.yap.pseudonator.io/k3d-k3s-default created\n"+
+		"cluster.yap.pseudonator.io/minikube created\n", out.String())
+
+	// The shared registry is created before any cluster. A registry
+	// with one cluster is left to that cluster's controller.
+	assert.Equal(t, map[string]string{
+		"shared-registry":   "ensure",
+		"minikube-registry": "minikube",
+	}, f.registryCreatedBy)
+}
+
+// Fake cluster controllers that share state, like real controllers
+// share a kubeconfig.
+type fakeClusterAppliers struct {
+	mu             sync.Mutex
+	applied        []string
+	errors         map[string]error
+	currentContext string
+	rollback       bool
+	// Who created each registry: a cluster name, or "ensure"
+	// for EnsureRegistry.
+	registryCreatedBy map[string]string
+}
+
+func newFakeClusterAppliers() *fakeClusterAppliers {
+	return &fakeClusterAppliers{errors: make(map[string]error), registryCreatedBy: make(map[string]string)}
+}
+
+func (f *fakeClusterAppliers) new(streams genericclioptions.IOStreams) (clusterApplier, error) {
+	return &fakeClusterApplier{shared: f, streams: streams}, nil
+}
+
+type fakeClusterApplier struct {
+	shared  *fakeClusterAppliers
+	streams genericclioptions.IOStreams
+}
+
+func (a *fakeClusterApplier) Apply(ctx context.Context, c *api.Cluster) (*api.Cluster, error) {
+	c = c.DeepCopy()
+	cluster.FillDefaults(c)
+	_, _ = fmt.Fprintf(a.streams.ErrOut, "Creating %s\n", c.Name)
+
+	a.shared.mu.Lock()
+	defer a.shared.mu.Unlock()
+	err := a.shared.errors[c.Name]
+	if err != nil {
+		return nil, err
+	}
+	if c.Registry != "" && a.shared.registryCreatedBy[c.Registry] == "" {
+		// Like the real controller, create the registry if it's missing.
+		a.shared.registryCreatedBy[c.Registry] = c.Name
+	}
+	a.shared.applied = append(a.shared.applied, c.Name)
+	return c, nil
+}
+
+func (a *fakeClusterApplier) SetRecreateConfirmer(confirm cluster.RecreateConfirmer) {}
+
+func (a *fakeClusterApplier) SetRollbackOnFailure(rollback bool) {
+	a.shared.mu.Lock()
+	defer a.shared.mu.Unlock()
+	a.shared.rollback = rollback
+}
+
+func (a *fakeClusterApplier) EnsureRegistry(ctx context.Context, name string) (*api.Registry, error) {
+	a.shared.mu.Lock()
+	defer a.shared.mu.Unlock()
+	if a.shared.registryCreatedBy[name] == "" {
+		a.shared.registryCreatedBy[name] = "ensure"
+	}
+	return &api.Registry{Name: name}, nil
+}
+
+func (a *fakeClusterApplier) UseContext(name string) error {
+	a.shared.mu.Lock()
+	defer a.shared.mu.Unlock()
+	a.shared.currentContext = name
+	return nil
+}
+
+func TestApplyValues(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	f := newFakeClusterAppliers()
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte("apiVersion: yap.pseudonator.io/v1alpha1\nkind: Cluster\n" +
+		"product: kind\nname: {{ .Values.cluster.name }}\n"))
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	dev := filepath.Join(dir, "dev.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("cluster:\n  name: kind-base\n  cpus: 2\n"), 0644))
+	require.NoError(t, os.WriteFile(dev, []byte("cluster:\n  name: kind-dev\n"), 0644))
+
+	cmd := o.Command()
+	require.NoError(t, cmd.Flags().Set("values", base))
+	require.NoError(t, cmd.Flags().Set("values", dev))
+
+	err := o.run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "cluster.yap.pseudonator.io/kind-dev created\n", out.String())
+}
+
+func TestApplyExpandEnvUnset(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	o.ExpandEnv = true
+	f := newFakeClusterAppliers()
+	o.clusterApplierFactory = f.new
+	_, _ = in.Write([]byte("apiVersion: yap.pseudonator.io/v1alpha1\nkind: Cluster\n" +
+		"product: kind\nname: ${YAP_TEST_UNSET_NAME}\n"))
+
+	err := o.run(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "visiting stdin: document 1, line 4, column 7: variable YAP_TEST_UNSET_NAME is not set")
+	}
+	assert.Empty(t, f.applied)
+}
@@ -7,11 +7,13 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/pseudonator/yap/pkg/api"
 	"github.com/pseudonator/yap/pkg/cluster"
+	"github.com/pseudonator/yap/pkg/encoding"
 	clusterid "github.com/pseudonator/yap/pkg/internal/cluster"
 )
 
@@ -20,6 +22,18 @@ type CreateClusterOptions struct {
 	genericclioptions.IOStreams
 
 	Cluster *api.Cluster
+
+	// When set, delete the cluster if it fails to create,
+	// or is interrupted while creating.
+	RollbackOnFailure bool
+
+	// When set, render the cluster from this ClusterTemplate,
+	// with parameters from Set.
+	Template string
+	Set      []string
+
+	// The command's flags, to tell which ones were set explicitly.
+	flags *pflag.FlagSet
 }
 
 func NewCreateClusterOptions() *CreateClusterOptions {
@@ -31,6 +45,9 @@ func NewCreateClusterOptions() *CreateClusterOptions {
 			Minikube: &api.MinikubeCluster{},
 		},
 	}
+	// --template names a ClusterTemplate. Go templates still work
+	// with -o go-template=...
+	o.PrintFlags.TemplatePrinterFlags.TemplateArgument = nil
 	return o
 }
 
@@ -39,9 +56,10 @@ func (o *CreateClusterOptions) Command() *cobra.Command {
 		Use:   "cluster [product]",
 		Short: "Create a cluster with the given local Kubernetes product",
 		Example: "  yap create cluster docker-desktop\n" +
-			"  yap create cluster kind",
+			"  yap create cluster kind\n" +
+			"  yap create cluster --template team-kind --set workers=2",
 		Run:  o.Run,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.RangeArgs(0, 1),
 	}
 
 	cmd.SetOut(o.Out)
@@ -53,12 +71,21 @@ func (o *CreateClusterOptions) Command() *cobra.Command {
 		o.Cluster.MinCPUs, "Sets the minimum CPUs for the cluster")
 	cmd.Flags().StringVar(&o.Cluster.KubernetesVersion, "kubernetes-version",
 		o.Cluster.KubernetesVersion, "Sets the kubernetes version for the cluster, if possible")
+	cmd.Flags().StringVar(&o.Cluster.NodeImage, "node-image",
+		o.Cluster.NodeImage, "Sets the node image for the cluster (only applicable to a kind cluster)")
 	cmd.Flags().StringSliceVar(&o.Cluster.Minikube.StartFlags, "minikube-start-flags",
 		o.Cluster.Minikube.StartFlags, "Minikube extra start flags (only applicable to a minikube cluster)")
 	cmd.Flags().StringSliceVar(&o.Cluster.Minikube.ExtraConfigs, "minikube-extra-configs",
 		o.Cluster.Minikube.ExtraConfigs, "Minikube extra configs (only applicable to a minikube cluster)")
 	cmd.Flags().StringVar(&o.Cluster.Minikube.ContainerRuntime, "minikube-container-runtime",
 		o.Cluster.Minikube.ContainerRuntime, "Minikube container runtime (only applicable to a minikube cluster)")
+	cmd.Flags().BoolVar(&o.RollbackOnFailure, "rollback-on-failure", o.RollbackOnFailure,
+		"If true, delete the cluster if it fails to create or is interrupted while creating, and remove its kubectl context.")
+	cmd.Flags().StringVar(&o.Template, "template", o.Template,
+		"Create the cluster from a ClusterTemplate: a name in ~/.yap/templates, a file, a URL, or - for stdin")
+	cmd.Flags().StringArrayVar(&o.Set, "set", o.Set,
+		"Sets a template parameter, as NAME=VALUE (only applicable with --template). May be repeated")
+	o.flags = cmd.Flags()
 
 	return cmd
 }
@@ -70,7 +97,14 @@ func (o *CreateClusterOptions) Run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	err = o.run(controller, args[0])
+	product := ""
+	if len(args) > 0 {
+		product = args[0]
+	}
+
+	ctx, cancel := signalContext()
+	err = o.run(ctx, controller, product)
+	cancel()
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
 		os.Exit(1)
@@ -80,19 +114,32 @@ func (o *CreateClusterOptions) Run(cmd *cobra.Command, args []string) {
 type clusterCreator interface {
 	Apply(ctx context.Context, cluster *api.Cluster) (*api.Cluster, error)
 	Get(ctx context.Context, name string) (*api.Cluster, error)
+	SetRollbackOnFailure(rollback bool)
 }
 
-func (o *CreateClusterOptions) run(controller clusterCreator, product string) error {
-	o.Cluster.Product = product
+func (o *CreateClusterOptions) run(ctx context.Context, controller clusterCreator, product string) error {
+	if o.Template != "" {
+		err := o.renderTemplate(product)
+		if err != nil {
+			return err
+		}
+	} else if product == "" {
+		return fmt.Errorf("Missing product. Specify a product, or create from a template with --template")
+	} else if len(o.Set) > 0 {
+		return fmt.Errorf("--set is only applicable with --template")
+	} else {
+		o.Cluster.Product = product
+	}
 
 	// Zero out the minikube config if not used.
-	if product != string(clusterid.ProductMinikube) || cmp.Equal(o.Cluster.Minikube, &api.MinikubeCluster{}) {
+	if o.Cluster.Product != string(clusterid.ProductMinikube) ||
+		o.Cluster.Minikube == nil ||
+		cmp.Equal(o.Cluster.Minikube, &api.MinikubeCluster{}) {
 		o.Cluster.Minikube = nil
 	}
 
 	cluster.FillDefaults(o.Cluster)
 
-	ctx := context.Background()
 	_, err := controller.Get(ctx, o.Cluster.Name)
 	if err == nil {
 		return fmt.Errorf("Cannot create cluster: already exists")
@@ -100,6 +147,7 @@ func (o *CreateClusterOptions) run(controller clusterCreator, product string) er
 		return fmt.Errorf("Cannot check cluster: %v", err)
 	}
 
+	controller.SetRollbackOnFailure(o.RollbackOnFailure)
 	applied, err := controller.Apply(ctx, o.Cluster)
 	if err != nil {
 		return err
@@ -112,3 +160,67 @@ func (o *CreateClusterOptions) run(controller clusterCreator, product string) er
 
 	return printer.PrintObj(applied, o.Out)
 }
+
+// Replaces the cluster with one rendered from the template.
+//
+// Flags that were set explicitly override the template.
+func (o *CreateClusterOptions) renderTemplate(product string) error {
+	values, err := parseTemplateValues(o.Set)
+	if err != nil {
+		return err
+	}
+
+	t, err := loadClusterTemplate(o.Template, o.In, defaultTemplateDir())
+	if err != nil {
+		return err
+	}
+
+	rendered, err := encoding.RenderClusterTemplate(t, values)
+	if err != nil {
+		return err
+	}
+
+	if product != "" && rendered.Product != product {
+		return fmt.Errorf("template %s creates %s clusters, not %s", t.Name, orNone(rendered.Product), product)
+	}
+
+	flags := o.Cluster
+	if o.flagChanged("name") {
+		rendered.Name = flags.Name
+	}
+	if o.flagChanged("min-cpus") {
+		rendered.MinCPUs = flags.MinCPUs
+	}
+	if o.flagChanged("kubernetes-version") {
+		rendered.KubernetesVersion = flags.KubernetesVersion
+	}
+	if o.flagChanged("node-image") {
+		rendered.NodeImage = flags.NodeImage
+	}
+
+	minikube := rendered.Minikube
+	if minikube == nil {
+		minikube = &api.MinikubeCluster{}
+	}
+	if o.flagChanged("minikube-start-flags") {
+		minikube.StartFlags = flags.Minikube.StartFlags
+	}
+	if o.flagChanged("minikube-extra-configs") {
+		minikube.ExtraConfigs = flags.Minikube.ExtraConfigs
+	}
+	if o.flagChanged("minikube-container-runtime") {
+		minikube.ContainerRuntime = flags.Minikube.ContainerRuntime
+	}
+	if !cmp.Equal(minikube, &api.MinikubeCluster{}) {
+		rendered.Minikube = minikube
+	}
+
+	o.Cluster = rendered
+	return nil
+}
+
+// Whether the user set the flag on the command line. Comparing against
+// the zero value would let a flag default override the template.
+func (o *CreateClusterOptions) flagChanged(name string) bool {
+	return o.flags != nil && o.flags.Changed(name)
+}
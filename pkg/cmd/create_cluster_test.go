@@ -2,15 +2,20 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
 )
 
 func TestCreateCluster(t *testing.T) {
@@ -19,7 +24,7 @@ func TestCreateCluster(t *testing.T) {
 	o.IOStreams = streams
 
 	fcc := &fakeClusterController{}
-	err := o.run(fcc, "kind")
+	err := o.run(context.Background(), fcc, "kind")
 	require.NoError(t, err)
 	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind created\n", out.String())
 	assert.Equal(t, "kind-kind", fcc.lastApplyName)
@@ -27,8 +32,12 @@ func TestCreateCluster(t *testing.T) {
 
 type fakeClusterController struct {
 	clusters       map[string]*api.Cluster
+	plans          map[string]*cluster.Plan
 	lastApplyName  string
 	lastDeleteName string
+	lastWait       string
+	lastLifecycle  string
+	rollback       bool
 	nextError      error
 }
 
@@ -50,6 +59,20 @@ func (cd *fakeClusterController) Apply(ctx context.Context, cluster *api.Cluster
 	return cluster, nil
 }
 
+func (cd *fakeClusterController) SetRollbackOnFailure(rollback bool) {
+	cd.rollback = rollback
+}
+
+func (cd *fakeClusterController) Plan(ctx context.Context, desired *api.Cluster) (*cluster.Plan, error) {
+	c := desired.DeepCopy()
+	cluster.FillDefaults(c)
+	plan, ok := cd.plans[c.Name]
+	if ok {
+		return plan, nil
+	}
+	return &cluster.Plan{Name: c.Name, Product: c.Product, Action: cluster.PlanActionCreate}, nil
+}
+
 func (cd *fakeClusterController) Get(ctx context.Context, name string) (*api.Cluster, error) {
 	cluster, ok := cd.clusters[name]
 	if ok {
@@ -57,3 +80,54 @@ func (cd *fakeClusterController) Get(ctx context.Context, name string) (*api.Clu
 	}
 	return nil, apierrors.NewNotFound(schema.GroupResource{Group: "yap.pseudonator.io", Resource: "clusters"}, name)
 }
+
+func (cd *fakeClusterController) List(ctx context.Context, options cluster.ListOptions) (*api.ClusterList, error) {
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range cd.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &api.ClusterList{TypeMeta: cluster.ListTypeMeta()}
+	for _, name := range names {
+		if selector.Matches(labels.Set(cd.clusters[name].Labels)) {
+			item := *cd.clusters[name]
+			item.TypeMeta = cluster.TypeMeta()
+			result.Items = append(result.Items, item)
+		}
+	}
+	return result, nil
+}
+
+func (cd *fakeClusterController) WaitForCondition(ctx context.Context, name string, t api.ClusterConditionType, timeout time.Duration) (*api.Cluster, error) {
+	if cd.nextError != nil {
+		return nil, cd.nextError
+	}
+	cd.lastWait = fmt.Sprintf("%s %s %s", name, t, timeout)
+	return cd.Get(ctx, name)
+}
+
+func (cd *fakeClusterController) Stop(ctx context.Context, name string) error {
+	return cd.lifecycle("stop", name)
+}
+
+func (cd *fakeClusterController) Start(ctx context.Context, name string) error {
+	return cd.lifecycle("start", name)
+}
+
+func (cd *fakeClusterController) Pause(ctx context.Context, name string) error {
+	return cd.lifecycle("pause", name)
+}
+
+func (cd *fakeClusterController) lifecycle(action, name string) error {
+	if cd.nextError != nil {
+		return cd.nextError
+	}
+	cd.lastLifecycle = fmt.Sprintf("%s %s", action, name)
+	return nil
+}
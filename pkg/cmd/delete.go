@@ -12,16 +12,19 @@ import (
 
 	"github.com/pseudonator/yap/pkg/api"
 	"github.com/pseudonator/yap/pkg/cluster"
-	"github.com/pseudonator/yap/pkg/visitor"
+	"github.com/pseudonator/yap/pkg/registry"
 )
 
 type DeleteOptions struct {
 	*genericclioptions.PrintFlags
 	*genericclioptions.FileNameFlags
+	ManifestFlags
 	genericclioptions.IOStreams
 
 	IgnoreNotFound bool
 	Filenames      []string
+	Recursive      bool
+	LabelSelector  string
 
 	// We currently only support two modes - "true" and "false".
 	// But we expect that there may be more modes in the future
@@ -29,6 +32,7 @@ type DeleteOptions struct {
 	Cascade string
 
 	clusterController clusterController
+	registryDeleter   deleter
 }
 
 func NewDeleteOptions() *DeleteOptions {
@@ -36,7 +40,7 @@ func NewDeleteOptions() *DeleteOptions {
 		PrintFlags: genericclioptions.NewPrintFlags("deleted"),
 		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
 	}
-	o.FileNameFlags = &genericclioptions.FileNameFlags{Filenames: &o.Filenames}
+	o.FileNameFlags = &genericclioptions.FileNameFlags{Filenames: &o.Filenames, Recursive: &o.Recursive}
 	return o
 }
 
@@ -45,14 +49,18 @@ func (o *DeleteOptions) Command() *cobra.Command {
 		Use:   "delete -f FILENAME",
 		Short: "Delete a currently running cluster",
 		Example: "  yap delete -f cluster.yaml\n" +
-			"  yap delete cluster minikube",
+			"  yap delete cluster minikube\n" +
+			"  yap delete -l ephemeral=true\n" +
+			"  yap delete registry yap-registry",
 		Run: o.Run,
 	}
 
 	cmd.SetOut(o.Out)
 	cmd.SetErr(o.ErrOut)
 	o.FileNameFlags.AddFlags(cmd.Flags())
+	o.ManifestFlags.AddFlags(cmd.Flags())
 
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter clusters on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.")
 	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If the requested object does not exist the command will return exit code 0.")
 	cmd.Flags().StringVar(&o.Cascade, "cascade", "false",
 		"If 'true', objects will be deleted recursively. "+
@@ -62,7 +70,9 @@ func (o *DeleteOptions) Command() *cobra.Command {
 }
 
 func (o *DeleteOptions) Run(cmd *cobra.Command, args []string) {
-	err := o.run(args)
+	ctx, cancel := signalContext()
+	err := o.run(ctx, args)
+	cancel()
 	if err != nil {
 		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
 		os.Exit(1)
@@ -76,16 +86,21 @@ type deleter interface {
 type clusterController interface {
 	deleter
 	Get(ctx context.Context, name string) (*api.Cluster, error)
+	List(ctx context.Context, options cluster.ListOptions) (*api.ClusterList, error)
 }
 
-func (o *DeleteOptions) run(args []string) error {
+func (o *DeleteOptions) run(ctx context.Context, args []string) error {
 	err := o.validateCascade()
 	if err != nil {
 		return err
 	}
 
-	ctx := context.TODO()
-	resources, err := o.parseExplicitResources(args)
+	var resources []runtime.Object
+	if o.LabelSelector != "" {
+		resources, err = o.selectClusters(ctx, args)
+	} else {
+		resources, err = o.parseExplicitResources(args)
+	}
 	if err != nil {
 		return err
 	}
@@ -126,6 +141,19 @@ func (o *DeleteOptions) run(args []string) error {
 			if err != nil {
 				return err
 			}
+
+			if o.Cascade == "true" && cluster != nil && cluster.Registry != "" {
+				err = o.deleteRegistry(ctx, cluster.Registry, true)
+				if err != nil {
+					return err
+				}
+			}
+		case *api.Registry:
+			registry.FillDefaults(resource)
+			err = o.deleteRegistry(ctx, resource.Name, o.IgnoreNotFound)
+			if err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("cannot delete: %T", resource)
 		}
@@ -137,19 +165,14 @@ func (o *DeleteOptions) parseExplicitResources(args []string) ([]runtime.Object,
 	hasFiles := len(o.Filenames) > 0
 	hasNames := len(args) >= 2
 	if !(hasFiles || hasNames) {
-		return nil, fmt.Errorf("Expected resources, specified as files ('yap delete -f') or names ('yap delete cluster foo`)")
+		return nil, fmt.Errorf("Expected resources, specified as files ('yap delete -f'), names ('yap delete cluster foo'), or a selector ('yap delete -l key=value')")
 	}
 	if hasFiles && hasNames {
 		return nil, fmt.Errorf("Can only specify one of {files, resource names}")
 	}
 
 	if hasFiles {
-		visitors, err := visitor.FromStrings(o.Filenames, o.In)
-		if err != nil {
-			return nil, err
-		}
-
-		return visitor.DecodeAll(visitors)
+		return o.ManifestFlags.decode(o.Filenames, o.Recursive, o.In)
 	}
 
 	var resources []runtime.Object
@@ -163,12 +186,85 @@ func (o *DeleteOptions) parseExplicitResources(args []string) ([]runtime.Object,
 				Name:     name,
 			})
 		}
+	case "registry", "registries":
+		for _, name := range names {
+			resources = append(resources, &api.Registry{
+				TypeMeta: registry.TypeMeta(),
+				Name:     name,
+			})
+		}
 	default:
 		return nil, fmt.Errorf("Unrecognized type: %s", t)
 	}
 	return resources, nil
 }
 
+// Lists the clusters matching the label selector.
+func (o *DeleteOptions) selectClusters(ctx context.Context, args []string) ([]runtime.Object, error) {
+	if len(o.Filenames) > 0 || len(args) >= 2 {
+		return nil, fmt.Errorf("Can only specify one of {files, resource names, selector}")
+	}
+	if len(args) == 1 && args[0] != "cluster" && args[0] != "clusters" {
+		return nil, fmt.Errorf("Selectors are only supported for clusters, found type: %s", args[0])
+	}
+
+	controller, err := o.getClusterController()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := controller.List(ctx, cluster.ListOptions{LabelSelector: o.LabelSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Items) == 0 {
+		_, _ = fmt.Fprintln(o.ErrOut, "No resources found")
+	}
+
+	var resources []runtime.Object
+	for i := range list.Items {
+		resources = append(resources, &list.Items[i])
+	}
+	return resources, nil
+}
+
+// Deletes the registry with the given name, and prints it.
+func (o *DeleteOptions) deleteRegistry(ctx context.Context, name string, ignoreNotFound bool) error {
+	deleter, err := o.getRegistryDeleter()
+	if err != nil {
+		return err
+	}
+
+	err = deleter.Delete(ctx, name)
+	if err != nil {
+		if ignoreNotFound && errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	printer, err := toPrinter(o.PrintFlags)
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(&api.Registry{
+		TypeMeta: registry.TypeMeta(),
+		Name:     name,
+	}, o.Out)
+}
+
+func (o *DeleteOptions) getRegistryDeleter() (deleter, error) {
+	if o.registryDeleter == nil {
+		controller, err := registry.DefaultController(o.IOStreams)
+		if err != nil {
+			return nil, err
+		}
+		o.registryDeleter = controller
+	}
+	return o.registryDeleter, nil
+}
+
 func (o *DeleteOptions) getClusterController() (clusterController, error) {
 	if o.clusterController == nil {
 		controller, err := cluster.DefaultController(o.IOStreams)
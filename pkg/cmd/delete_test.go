@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"io"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,7 +22,7 @@ func TestDeleteByName(t *testing.T) {
 
 	cd := &fakeClusterController{}
 	o.clusterController = cd
-	err := o.run([]string{"cluster", "kind-kind"})
+	err := o.run(context.Background(), []string{"cluster", "kind-kind"})
 	require.NoError(t, err)
 	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind deleted\n", out.String())
 	assert.Equal(t, "kind-kind", cd.lastDeleteName)
@@ -40,7 +41,7 @@ name: kind-kind
 	cd := &fakeClusterController{}
 	o.clusterController = cd
 	o.Filenames = []string{"-"}
-	err := o.run([]string{})
+	err := o.run(context.Background(), []string{})
 	require.NoError(t, err)
 	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind deleted\n", out.String())
 	assert.Equal(t, "kind-kind", cd.lastDeleteName)
@@ -59,7 +60,7 @@ product: kind
 	cd := &fakeClusterController{}
 	o.clusterController = cd
 	o.Filenames = []string{"-"}
-	err := o.run([]string{})
+	err := o.run(context.Background(), []string{})
 	require.NoError(t, err)
 	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind deleted\n", out.String())
 	assert.Equal(t, "kind-kind", cd.lastDeleteName)
@@ -73,7 +74,7 @@ func TestDeleteNotFound(t *testing.T) {
 	cd := &fakeClusterController{nextError: errors.NewNotFound(
 		schema.GroupResource{Group: "yap.pseudonator.io", Resource: "clusters"}, "garbage")}
 	o.clusterController = cd
-	err := o.run([]string{"cluster", "garbage"})
+	err := o.run(context.Background(), []string{"cluster", "garbage"})
 	if assert.Error(t, err) {
 		assert.Contains(t, err.Error(), `clusters.yap.pseudonator.io "garbage" not found`)
 	}
@@ -88,7 +89,7 @@ func TestDeleteIgnoreNotFound(t *testing.T) {
 		schema.GroupResource{Group: "yap.pseudonator.io", Resource: "clusters"}, "garbage")}
 	o.clusterController = cd
 	o.IgnoreNotFound = true
-	err := o.run([]string{"cluster", "garbage"})
+	err := o.run(context.Background(), []string{"cluster", "garbage"})
 	require.NoError(t, err)
 	assert.Equal(t, "", out.String())
 }
@@ -101,17 +102,57 @@ func TestDeleteCascade(t *testing.T) {
 	cd := &fakeClusterController{
 		clusters: map[string]*api.Cluster{
 			"kind-kind": &api.Cluster{
-				Name: "kind-kind",
+				Name:     "kind-kind",
+				Registry: "kind-registry",
 			},
 		},
 	}
+	rd := &fakeDeleter{}
 	o.clusterController = cd
+	o.registryDeleter = rd
 	o.Cascade = "true"
-	err := o.run([]string{"cluster", "kind-kind"})
+	err := o.run(context.Background(), []string{"cluster", "kind-kind"})
 	require.NoError(t, err)
 	assert.Equal(t,
-		"cluster.yap.pseudonator.io/kind-kind deleted\n",
+		"cluster.yap.pseudonator.io/kind-kind deleted\n"+
+			"registry.yap.pseudonator.io/kind-registry deleted\n",
 		out.String())
+	assert.Equal(t, "kind-registry", rd.lastName)
+}
+
+func TestDeleteNoCascade(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	cd := &fakeClusterController{
+		clusters: map[string]*api.Cluster{
+			"kind-kind": &api.Cluster{
+				Name:     "kind-kind",
+				Registry: "kind-registry",
+			},
+		},
+	}
+	rd := &fakeDeleter{}
+	o.clusterController = cd
+	o.registryDeleter = rd
+	err := o.run(context.Background(), []string{"cluster", "kind-kind"})
+	require.NoError(t, err)
+	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind deleted\n", out.String())
+	assert.Equal(t, "", rd.lastName)
+}
+
+func TestDeleteRegistryByName(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	rd := &fakeDeleter{}
+	o.registryDeleter = rd
+	err := o.run(context.Background(), []string{"registry", "kind-registry"})
+	require.NoError(t, err)
+	assert.Equal(t, "registry.yap.pseudonator.io/kind-registry deleted\n", out.String())
+	assert.Equal(t, "kind-registry", rd.lastName)
 }
 
 func TestDeleteCascadeStdin(t *testing.T) {
@@ -122,11 +163,13 @@ func TestDeleteCascadeStdin(t *testing.T) {
 	cd := &fakeClusterController{
 		clusters: map[string]*api.Cluster{
 			"kind-kind": &api.Cluster{
-				Name: "kind-kind",
+				Name:     "kind-kind",
+				Registry: "kind-registry",
 			},
 		},
 	}
 	o.clusterController = cd
+	o.registryDeleter = &fakeDeleter{}
 	o.Cascade = "true"
 	o.Filenames = []string{"-"}
 	_, _ = io.WriteString(in, `
@@ -134,10 +177,11 @@ apiVersion: yap.pseudonator.io/v1alpha1
 kind: Cluster
 product: kind
 `)
-	err := o.run(nil)
+	err := o.run(context.Background(), nil)
 	require.NoError(t, err)
 	assert.Equal(t,
-		"cluster.yap.pseudonator.io/kind-kind deleted\n",
+		"cluster.yap.pseudonator.io/kind-kind deleted\n"+
+			"registry.yap.pseudonator.io/kind-registry deleted\n",
 		out.String())
 }
 
@@ -147,7 +191,7 @@ func TestDeleteCascadeInvalid(t *testing.T) {
 	o.IOStreams = streams
 
 	o.Cascade = "xxx"
-	err := o.run([]string{"cluster", "kind-kind"})
+	err := o.run(context.Background(), []string{"cluster", "kind-kind"})
 	if assert.Error(t, err) {
 		require.Contains(t, err.Error(), "Invalid cascade: xxx. Valid values: true, false.")
 	}
@@ -165,3 +209,72 @@ func (cd *fakeDeleter) Delete(ctx context.Context, name string) error {
 	cd.lastName = name
 	return nil
 }
+
+func TestDeleteBySelector(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	cd := &fakeClusterController{
+		clusters: map[string]*api.Cluster{
+			"kind-a": &api.Cluster{Name: "kind-a", Labels: map[string]string{"ephemeral": "true"}},
+			"kind-b": &api.Cluster{Name: "kind-b"},
+			"kind-c": &api.Cluster{Name: "kind-c", Labels: map[string]string{"ephemeral": "true"}},
+		},
+	}
+	o.clusterController = cd
+	o.LabelSelector = "ephemeral=true"
+	err := o.run(context.Background(), []string{})
+	require.NoError(t, err)
+	assert.Equal(t,
+		"cluster.yap.pseudonator.io/kind-a deleted\n"+
+			"cluster.yap.pseudonator.io/kind-c deleted\n",
+		out.String())
+	assert.Equal(t, []string{"kind-b"}, clusterNames(cd))
+}
+
+func TestDeleteBySelectorNoMatch(t *testing.T) {
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+
+	cd := &fakeClusterController{
+		clusters: map[string]*api.Cluster{
+			"kind-b": &api.Cluster{Name: "kind-b"},
+		},
+	}
+	o.clusterController = cd
+	o.LabelSelector = "ephemeral=true"
+	err := o.run(context.Background(), []string{"clusters"})
+	require.NoError(t, err)
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "No resources found\n", errOut.String())
+	assert.Equal(t, []string{"kind-b"}, clusterNames(cd))
+}
+
+func TestDeleteBySelectorAndName(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewDeleteOptions()
+	o.IOStreams = streams
+	o.clusterController = &fakeClusterController{}
+	o.LabelSelector = "ephemeral=true"
+
+	err := o.run(context.Background(), []string{"cluster", "kind-kind"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Can only specify one of {files, resource names, selector}")
+	}
+
+	err = o.run(context.Background(), []string{"registry"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Selectors are only supported for clusters")
+	}
+}
+
+func clusterNames(cd *fakeClusterController) []string {
+	names := []string{}
+	for name := range cd.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
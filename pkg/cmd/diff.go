@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+type DiffOptions struct {
+	*genericclioptions.FileNameFlags
+	ManifestFlags
+	genericclioptions.IOStreams
+
+	Filenames []string
+	Recursive bool
+	Output    string
+
+	clusterPlanner clusterPlanner
+}
+
+func NewDiffOptions() *DiffOptions {
+	o := &DiffOptions{
+		IOStreams: genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+	}
+	o.FileNameFlags = &genericclioptions.FileNameFlags{Filenames: &o.Filenames, Recursive: &o.Recursive}
+	return o
+}
+
+func (o *DiffOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "diff -f FILENAME",
+		Short: "Show what apply would change, without changing anything",
+		Long: "Show what apply would change, without changing anything.\n\n" +
+			"For each cluster, prints whether apply would create it, delete and recreate it, " +
+			"restart its machine, or leave it alone.\n\n" +
+			"Exits with status 1 if apply would change anything, and status 2 on error, " +
+			"so that it can be used to gate changes in CI.",
+		Example: "  yap diff -f cluster.yaml\n" +
+			"  yap diff -f cluster.yaml -o json",
+		Run: o.Run,
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.FileNameFlags.AddFlags(cmd.Flags())
+	o.ManifestFlags.AddFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json")
+
+	return cmd
+}
+
+func (o *DiffOptions) Run(cmd *cobra.Command, args []string) {
+	if len(o.Filenames) == 0 {
+		_, _ = fmt.Fprintf(o.ErrOut, "Expected source files with -f\n")
+		os.Exit(2)
+	}
+
+	hasChanges, err := o.run()
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(2)
+	}
+	if hasChanges {
+		os.Exit(1)
+	}
+}
+
+// Prints the plans, and returns whether any of them would change a cluster.
+func (o *DiffOptions) run() (bool, error) {
+	if o.Output != "" && o.Output != "json" {
+		return false, fmt.Errorf("unsupported output format: %s. Allowed formats: json", o.Output)
+	}
+
+	objects, err := o.ManifestFlags.decode(o.Filenames, o.Recursive, o.In)
+	if err != nil {
+		return false, err
+	}
+
+	plans, err := planObjects(context.TODO(), o.getClusterPlanner, objects, o.ErrOut)
+	if err != nil {
+		return false, err
+	}
+
+	err = printPlans(o.Out, plans, o.Output)
+	if err != nil {
+		return false, err
+	}
+
+	for _, plan := range plans {
+		if plan.HasChanges() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (o *DiffOptions) getClusterPlanner() (clusterPlanner, error) {
+	if o.clusterPlanner == nil {
+		controller, err := cluster.DefaultController(o.IOStreams)
+		if err != nil {
+			return nil, err
+		}
+		o.clusterPlanner = controller
+	}
+	return o.clusterPlanner, nil
+}
+
+type clusterPlanner interface {
+	Plan(ctx context.Context, desired *api.Cluster) (*cluster.Plan, error)
+}
+
+// Plans each of the objects. The planner is created lazily, so that we
+// don't connect to anything if there's nothing to plan.
+func planObjects(ctx context.Context, getPlanner func() (clusterPlanner, error), objects []runtime.Object, errOut io.Writer) ([]*cluster.Plan, error) {
+	plans := []*cluster.Plan{}
+	for _, obj := range objects {
+		switch obj := obj.(type) {
+		case *api.Cluster:
+			planner, err := getPlanner()
+			if err != nil {
+				return nil, err
+			}
+
+			plan, err := planner.Plan(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, plan)
+
+		case *api.Registry:
+			_, _ = fmt.Fprintf(errOut, "Skipping registry %s: diff only supports clusters\n", obj.Name)
+
+		case *api.ClusterTemplate:
+			return nil, templateNotApplicableError(obj)
+
+		default:
+			return nil, fmt.Errorf("unrecognized type: %T", obj)
+		}
+	}
+	return plans, nil
+}
+
+// Prints the plans as JSON, or as one line per cluster followed by any
+// config diff.
+func printPlans(out io.Writer, plans []*cluster.Plan, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(plans, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	}
+
+	for _, plan := range plans {
+		line := fmt.Sprintf("cluster %s: %s", plan.Name, plan.Action)
+		if plan.Reason != "" {
+			line = fmt.Sprintf("%s (%s)", line, plan.Reason)
+		}
+		_, err := fmt.Fprintln(out, line)
+		if err != nil {
+			return err
+		}
+
+		if plan.Diff != "" {
+			_, err = fmt.Fprintln(out, indent(strings.TrimRight(plan.Diff, "\n"), "  "))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+const kindAndMinikubeYAML = `apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+---
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: minikube
+`
+
+func newFakePlanner() *fakeClusterController {
+	return &fakeClusterController{
+		plans: map[string]*cluster.Plan{
+			"kind-kind": &cluster.Plan{Name: "kind-kind", Product: "kind", Action: cluster.PlanActionNoOp},
+			"minikube": &cluster.Plan{
+				Name:    "minikube",
+				Product: "minikube",
+				Action:  cluster.PlanActionRecreate,
+				Reason:  "desired Minikube config does not match current",
+				Diff:    "- a\n+ b\n",
+			},
+		},
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDiffOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	o.clusterPlanner = newFakePlanner()
+	_, _ = in.Write([]byte(kindAndMinikubeYAML))
+
+	hasChanges, err := o.run()
+	require.NoError(t, err)
+	assert.True(t, hasChanges)
+	assert.Equal(t, `cluster kind-kind: no-op
+cluster minikube: recreate (desired Minikube config does not match current)
+  - a
+  + b
+`, out.String())
+}
+
+func TestDiffJSON(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewDiffOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	o.Output = "json"
+	o.clusterPlanner = newFakePlanner()
+	_, _ = in.Write([]byte(`apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+`))
+
+	hasChanges, err := o.run()
+	require.NoError(t, err)
+	assert.False(t, hasChanges)
+	assert.Equal(t, `[
+  {
+    "name": "kind-kind",
+    "product": "kind",
+    "action": "no-op"
+  }
+]
+`, out.String())
+}
+
+func TestDiffBadOutput(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewDiffOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	o.Output = "yaml"
+
+	_, err := o.run()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unsupported output format: yaml")
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	o.DryRun = true
+	planner := newFakePlanner()
+	o.clusterPlanner = planner
+	_, _ = in.Write([]byte(kindAndMinikubeYAML))
+
+	err := o.run(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "cluster minikube: recreate")
+	assert.Equal(t, "", planner.lastApplyName)
+}
+
+func TestApplyDryRunBadOutput(t *testing.T) {
+	for _, format := range []string{"yaml", "name"} {
+		streams, _, _, _ := genericclioptions.NewTestIOStreams()
+		o := NewApplyOptions()
+		o.IOStreams = streams
+		o.Filenames = []string{"-"}
+		o.DryRun = true
+		o.PrintFlags.OutputFormat = &format
+
+		err := o.run(context.Background())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "unsupported output format: "+format)
+		}
+	}
+}
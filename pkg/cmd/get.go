@@ -2,20 +2,27 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 
 	"github.com/pseudonator/yap/pkg/api"
 	"github.com/pseudonator/yap/pkg/cluster"
+	fmtprinters "github.com/pseudonator/yap/pkg/internal/printers"
+	"github.com/pseudonator/yap/pkg/registry"
 )
 
 type GetOptions struct {
@@ -24,6 +31,10 @@ type GetOptions struct {
 	StartTime      time.Time
 	IgnoreNotFound bool
 	FieldSelector  string
+	LabelSelector  string
+	SortBy         string
+	NoHeaders      bool
+	Watch          bool
 }
 
 func NewGetOptions() *GetOptions {
@@ -47,6 +58,11 @@ https://kubernetes.io/docs/reference/kubectl/cheatsheet/#formatting-output
 `,
 		Example: "  yap get\n" +
 			"  yap get cluster microk8s -o yaml\n" +
+			"  yap get registries\n" +
+			"  yap get clusters -l team=payments --field-selector status.error!=\n" +
+			"  yap get clusters --watch -o json\n" +
+			"  yap get -o wide --sort-by .status.kubernetesVersion\n" +
+			"  yap get -o custom-columns=NAME:.name,CPUS:.status.cpus --no-headers\n" +
 			"  yap get cluster kind-kind -o template --template '{{.status.localRegistryHosting.host}}'\n",
 		Run:  o.Run,
 		Args: cobra.MaximumNArgs(2),
@@ -55,9 +71,15 @@ https://kubernetes.io/docs/reference/kubectl/cheatsheet/#formatting-output
 	cmd.SetOut(o.Out)
 	cmd.SetErr(o.ErrOut)
 	o.PrintFlags.AddFlags(cmd)
+	formats := append(o.PrintFlags.AllowedFormats(), "wide", "custom-columns")
+	cmd.Flags().Lookup("output").Usage = fmt.Sprintf("Output format. One of: (%s).", strings.Join(formats, ", "))
 
 	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If the requested object does not exist the command will return exit code 0.")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter clusters on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort list types using this field specification. The field specification is expressed as a JSONPath expression (e.g. '{.status.cpus}').")
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default, wide, or custom-column output format, don't print headers (default print headers).")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "After listing/getting the requested clusters, watch for changes.")
 
 	return cmd
 }
@@ -77,6 +99,29 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 
+		if o.Watch {
+			name := ""
+			if len(args) >= 2 {
+				name = args[1]
+			}
+			w, err := c.Watch(ctx, cluster.ListOptions{
+				FieldSelector: withNameSelector(o.FieldSelector, name),
+				LabelSelector: o.LabelSelector,
+			})
+			if err != nil {
+				_, _ = fmt.Fprintf(o.ErrOut, "Watch clusters: %v\n", err)
+				os.Exit(1)
+			}
+			defer w.Stop()
+
+			err = o.PrintWatch(w, name)
+			if err != nil {
+				_, _ = fmt.Fprintf(o.ErrOut, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		if len(args) >= 2 {
 			resource, err = normalizedGet(ctx, c, args[1])
 			if err != nil {
@@ -87,15 +132,48 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 				os.Exit(1)
 			}
 		} else {
-			resource, err = c.List(ctx, cluster.ListOptions{FieldSelector: o.FieldSelector})
+			resource, err = c.List(ctx, cluster.ListOptions{FieldSelector: o.FieldSelector, LabelSelector: o.LabelSelector})
 			if err != nil {
 				_, _ = fmt.Fprintf(o.ErrOut, "List clusters: %v\n", err)
 				os.Exit(1)
 			}
 		}
 
+	case "registry", "registries":
+		if o.Watch {
+			_, _ = fmt.Fprintf(o.ErrOut, "--watch is only supported for clusters\n")
+			os.Exit(1)
+		}
+		if o.LabelSelector != "" {
+			_, _ = fmt.Fprintf(o.ErrOut, "--selector is only supported for clusters\n")
+			os.Exit(1)
+		}
+
+		c, err := registry.DefaultController(o.IOStreams)
+		if err != nil {
+			_, _ = fmt.Fprintf(o.ErrOut, "Loading controller: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) >= 2 {
+			resource, err = c.Get(ctx, args[1])
+			if err != nil {
+				if errors.IsNotFound(err) && o.IgnoreNotFound {
+					os.Exit(0)
+				}
+				_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			resource, err = c.List(ctx, registry.ListOptions{FieldSelector: o.FieldSelector})
+			if err != nil {
+				_, _ = fmt.Fprintf(o.ErrOut, "List registries: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
 	default:
-		_, _ = fmt.Fprintf(o.ErrOut, "Unrecognized type: %s. Possible value: cluster.\n", t)
+		_, _ = fmt.Fprintf(o.ErrOut, "Unrecognized type: %s. Possible values: cluster, registry.\n", t)
 		os.Exit(1)
 	}
 
@@ -107,12 +185,41 @@ func (o *GetOptions) Run(cmd *cobra.Command, args []string) {
 }
 
 func (o *GetOptions) ToPrinter() (printers.ResourcePrinter, error) {
-	if !o.OutputFlagSpecified() {
-		return printers.NewTablePrinter(printers.PrintOptions{}), nil
+	return o.toPrinter(o.NoHeaders)
+}
+
+func (o *GetOptions) toPrinter(noHeaders bool) (printers.ResourcePrinter, error) {
+	format := o.outputFormat()
+	if o.isTableOutput() {
+		return printers.NewTablePrinter(printers.PrintOptions{
+			NoHeaders: noHeaders,
+			Wide:      format == "wide",
+		}), nil
+	}
+
+	if spec, ok := strings.CutPrefix(format, "custom-columns="); ok {
+		columns, err := fmtprinters.ParseCustomColumns(spec)
+		if err != nil {
+			return nil, err
+		}
+		return fmtprinters.NewCustomColumnsPrinter(columns, noHeaders)
 	}
 	return toPrinter(o.PrintFlags)
 }
 
+func (o *GetOptions) outputFormat() string {
+	if o.PrintFlags.OutputFormat == nil {
+		return ""
+	}
+	return *o.PrintFlags.OutputFormat
+}
+
+// Whether we print our own tables, rather than the raw objects.
+func (o *GetOptions) isTableOutput() bool {
+	format := o.outputFormat()
+	return format == "" || format == "wide"
+}
+
 func (o *GetOptions) Print(obj runtime.Object) error {
 	if obj == nil {
 		fmt.Println("No resources found")
@@ -124,6 +231,11 @@ func (o *GetOptions) Print(obj runtime.Object) error {
 		return err
 	}
 
+	err = fmtprinters.SortItems(obj, o.SortBy)
+	if err != nil {
+		return err
+	}
+
 	err = printer.PrintObj(o.transformForOutput(obj), o.Out)
 	if err != nil {
 		return err
@@ -131,12 +243,178 @@ func (o *GetOptions) Print(obj runtime.Object) error {
 	return nil
 }
 
+// PrintWatch prints each cluster event until the watch closes.
+//
+// Tables get one row per event, with a leading EVENT column. JSON output
+// is one event object per line, so that editors can stream it.
+// If name is non-empty, we only print events for that cluster.
+func (o *GetOptions) PrintWatch(w watch.Interface, name string) error {
+	printer, err := o.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	// Custom column printers print headers on every call, but we only want them on the first row.
+	_, hasHeaders := printer.(*fmtprinters.CustomColumnsPrinter)
+
+	table := &watchTablePrinter{
+		out:       o.Out,
+		wide:      o.outputFormat() == "wide",
+		noHeaders: o.NoHeaders,
+	}
+
+	for e := range w.ResultChan() {
+		cluster, isCluster := e.Object.(*api.Cluster)
+		if isCluster && name != "" && cluster.Name != name {
+			continue
+		}
+
+		if o.outputFormat() == "json" {
+			err := json.NewEncoder(o.Out).Encode(watchEvent{Type: e.Type, Object: e.Object})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if e.Type == watch.Error {
+			status, ok := e.Object.(*metav1.Status)
+			if ok {
+				_, _ = fmt.Fprintf(o.ErrOut, "Error: %s\n", status.Message)
+			}
+			continue
+		}
+
+		if !isCluster {
+			continue
+		}
+
+		if o.isTableOutput() {
+			err := table.printRow(e.Type, o.clustersAsTable([]api.Cluster{*cluster}).(*metav1.Table))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := printer.PrintObj(cluster, o.Out)
+		if err != nil {
+			return err
+		}
+
+		if hasHeaders {
+			hasHeaders = false
+			printer, err = o.toPrinter(true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// The minimum widths of watch table columns, so that rows line up
+// for typical values.
+var watchColumnWidths = map[string]int{
+	"Event": len(watch.Modified),
+	"Name":  20,
+}
+
+// Prints a watch table one row at a time.
+//
+// A tabwriter can only align the rows it has, so each column gets a fixed
+// width instead: wide enough for its header, the first row, and typical
+// values. A longer cell pushes the rest of its row over, and widens its
+// column for later rows.
+type watchTablePrinter struct {
+	out       io.Writer
+	wide      bool
+	noHeaders bool
+	widths    []int
+}
+
+func (p *watchTablePrinter) printRow(event watch.EventType, table *metav1.Table) error {
+	columns := []string{"Event"}
+	for _, c := range table.ColumnDefinitions {
+		if c.Priority == 0 || p.wide {
+			columns = append(columns, c.Name)
+		}
+	}
+
+	cells := []string{string(event)}
+	for i, c := range table.ColumnDefinitions {
+		if c.Priority == 0 || p.wide {
+			cells = append(cells, fmt.Sprintf("%v", table.Rows[0].Cells[i]))
+		}
+	}
+
+	if p.widths == nil {
+		p.widths = make([]int, len(columns))
+		for i, c := range columns {
+			p.widths[i] = watchColumnWidths[c]
+			if !p.noHeaders && len(c) > p.widths[i] {
+				p.widths[i] = len(c)
+			}
+			if len(cells[i]) > p.widths[i] {
+				p.widths[i] = len(cells[i])
+			}
+		}
+
+		if !p.noHeaders {
+			headers := make([]string, len(columns))
+			for i, c := range columns {
+				headers[i] = strings.ToUpper(c)
+			}
+			err := p.writeCells(headers)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return p.writeCells(cells)
+}
+
+func (p *watchTablePrinter) writeCells(cells []string) error {
+	line := strings.Builder{}
+	for i, cell := range cells {
+		if len(cell) > p.widths[i] {
+			p.widths[i] = len(cell)
+		}
+		line.WriteString(cell)
+		if i < len(cells)-1 {
+			line.WriteString(strings.Repeat(" ", p.widths[i]-len(cell)+3))
+		}
+	}
+	line.WriteString("\n")
+	_, err := io.WriteString(p.out, line.String())
+	return err
+}
+
+// Adds name=NAME to a field selector, so that a watch of one cluster
+// doesn't health-check the others.
+func withNameSelector(selector, name string) string {
+	if name == "" {
+		return selector
+	}
+	nameSelector := "name=" + fields.EscapeValue(name)
+	if selector == "" {
+		return nameSelector
+	}
+	return selector + "," + nameSelector
+}
+
+// The JSON form of a watch event, matching `kubectl get --watch -o json --output-watch-events`.
+type watchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object runtime.Object  `json:"object"`
+}
+
 func (o *GetOptions) OutputFlagSpecified() bool {
 	return o.PrintFlags.OutputFlagSpecified != nil && o.PrintFlags.OutputFlagSpecified()
 }
 
 func (o *GetOptions) transformForOutput(obj runtime.Object) runtime.Object {
-	if o.OutputFlagSpecified() {
+	if !o.isTableOutput() {
 		return obj
 	}
 
@@ -145,6 +423,10 @@ func (o *GetOptions) transformForOutput(obj runtime.Object) runtime.Object {
 		return o.clustersAsTable([]api.Cluster{*r})
 	case *api.ClusterList:
 		return o.clustersAsTable(r.Items)
+	case *api.Registry:
+		return o.registriesAsTable([]api.Registry{*r})
+	case *api.RegistryList:
+		return o.registriesAsTable(r.Items)
 	default:
 		return obj
 	}
@@ -170,6 +452,26 @@ func (o *GetOptions) clustersAsTable(clusters []api.Cluster) runtime.Object {
 				Name: "Age",
 				Type: "string",
 			},
+			metav1.TableColumnDefinition{
+				Name:     "Version",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "CPUs",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "Host",
+				Type:     "string",
+				Priority: 1,
+			},
+			metav1.TableColumnDefinition{
+				Name:     "Status",
+				Type:     "string",
+				Priority: 1,
+			},
 		},
 	}
 
@@ -185,15 +487,96 @@ func (o *GetOptions) clustersAsTable(clusters []api.Cluster) runtime.Object {
 			current = "*"
 		}
 
+		version := orNone(cluster.Status.KubernetesVersion)
+		host := orNone(cluster.Status.Host)
+
+		cpus := "<none>"
+		if cluster.Status.CPUs != 0 {
+			cpus = fmt.Sprintf("%d", cluster.Status.CPUs)
+		}
+
+		status := "Healthy"
+		if cluster.Status.Phase == api.ClusterPhaseStopped || cluster.Status.Phase == api.ClusterPhasePaused {
+			status = string(cluster.Status.Phase)
+		} else if cluster.Status.Error != "" {
+			status = cluster.Status.Error
+		} else if version == "<none>" {
+			status = "Unknown"
+		}
+
 		table.Rows = append(table.Rows, metav1.TableRow{
 			Cells: []interface{}{
 				current,
 				cluster.Name,
 				cluster.Product,
 				age,
+				version,
+				cpus,
+				host,
+				status,
 			},
 		})
 	}
 
 	return &table
 }
+
+func (o *GetOptions) registriesAsTable(registries []api.Registry) runtime.Object {
+	table := metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: "metav1.k8s.io"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			metav1.TableColumnDefinition{
+				Name: "Name",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Host Address",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Container Address",
+				Type: "string",
+			},
+			metav1.TableColumnDefinition{
+				Name: "Age",
+				Type: "string",
+			},
+		},
+	}
+
+	for _, registry := range registries {
+		age := "unknown"
+		cTime := registry.Status.CreationTimestamp.Time
+		if !cTime.IsZero() {
+			age = duration.ShortHumanDuration(o.StartTime.Sub(cTime))
+		}
+
+		hostAddress := "none"
+		if registry.Status.HostPort != 0 {
+			hostAddress = fmt.Sprintf("%s:%d", registry.Status.ListenAddress, registry.Status.HostPort)
+		}
+
+		containerAddress := "none"
+		if registry.Status.ContainerPort != 0 {
+			containerAddress = fmt.Sprintf("%s:%d", registry.Name, registry.Status.ContainerPort)
+		}
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				registry.Name,
+				hostAddress,
+				containerAddress,
+				age,
+			},
+		})
+	}
+
+	return &table
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
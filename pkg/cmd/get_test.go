@@ -7,10 +7,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"github.com/pseudonator/yap/pkg/api"
 	"github.com/pseudonator/yap/pkg/cluster"
+	"github.com/pseudonator/yap/pkg/registry"
 )
 
 var createTime = time.Unix(1500000000, 0)
@@ -53,6 +55,35 @@ func TestDefaultPrint(t *testing.T) {
 `)
 }
 
+func TestRegistryPrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	registryList := &api.RegistryList{
+		TypeMeta: registry.ListTypeMeta(),
+		Items: []api.Registry{
+			api.Registry{
+				TypeMeta: registry.TypeMeta(),
+				Name:     "kind-registry",
+				Status: api.RegistryStatus{
+					CreationTimestamp: metav1.Time{Time: createTime},
+					ListenAddress:     "127.0.0.1",
+					HostPort:          5001,
+					ContainerPort:     5000,
+				},
+			},
+		},
+	}
+
+	err := o.Print(o.transformForOutput(registryList))
+	require.NoError(t, err)
+	assert.Equal(t, `NAME            HOST ADDRESS     CONTAINER ADDRESS    AGE
+kind-registry   127.0.0.1:5001   kind-registry:5000   3y
+`, out.String())
+}
+
 func TestYAML(t *testing.T) {
 	streams, _, out, _ := genericclioptions.NewTestIOStreams()
 	o := NewGetOptions()
@@ -82,3 +113,211 @@ items:
 kind: ClusterList
 `, out.String())
 }
+
+func wideClusterList() *api.ClusterList {
+	return &api.ClusterList{
+		TypeMeta: cluster.ListTypeMeta(),
+		Items: []api.Cluster{
+			api.Cluster{
+				TypeMeta: clusterType,
+				Name:     "microk8s",
+				Product:  "microk8s",
+				Status: api.ClusterStatus{
+					CreationTimestamp: metav1.Time{Time: createTime},
+					Current:           true,
+					KubernetesVersion: "1.24.2",
+					CPUs:              4,
+					Host:              "127.0.0.1:16443",
+				},
+			},
+			api.Cluster{
+				TypeMeta: clusterType,
+				Name:     "kind-kind",
+				Product:  "KIND",
+				Status: api.ClusterStatus{
+					CreationTimestamp: metav1.Time{Time: createTime},
+					KubernetesVersion: "1.23.4",
+					CPUs:              8,
+					Host:              "127.0.0.1:51234",
+				},
+			},
+			api.Cluster{
+				TypeMeta: clusterType,
+				Name:     "minikube",
+				Product:  "minikube",
+				Status: api.ClusterStatus{
+					CreationTimestamp: metav1.Time{Time: createTime},
+					Error:             "connection refused",
+				},
+			},
+		},
+	}
+}
+
+func TestWidePrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	err := o.Command().Flags().Set("output", "wide")
+	require.NoError(t, err)
+
+	err = o.Print(wideClusterList())
+	require.NoError(t, err)
+	assert.Equal(t, `CURRENT   NAME        PRODUCT    AGE   VERSION   CPUS     HOST              STATUS
+*         microk8s    microk8s   3y    1.24.2    4        127.0.0.1:16443   Healthy
+          kind-kind   KIND       3y    1.23.4    8        127.0.0.1:51234   Healthy
+          minikube    minikube   3y    <none>    <none>   <none>            connection refused
+`, out.String())
+}
+
+func TestCustomColumnsPrint(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	err := o.Command().Flags().Set("output", "custom-columns=NAME:.name,CPUS:{.status.cpus},ERROR:status.error")
+	require.NoError(t, err)
+
+	err = o.Print(wideClusterList())
+	require.NoError(t, err)
+	assert.Equal(t, `NAME        CPUS     ERROR
+microk8s    4        <none>
+kind-kind   8        <none>
+minikube    <none>   connection refused
+`, out.String())
+}
+
+func TestCustomColumnsInvalid(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+
+	err := o.Command().Flags().Set("output", "custom-columns=NAME")
+	require.NoError(t, err)
+
+	err = o.Print(wideClusterList())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "expected <header>:<json-path-expr>")
+	}
+}
+
+func TestSortByNoHeaders(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	cmd := o.Command()
+	require.NoError(t, cmd.Flags().Set("sort-by", ".status.cpus"))
+	require.NoError(t, cmd.Flags().Set("no-headers", "true"))
+
+	err := o.Print(wideClusterList())
+	require.NoError(t, err)
+	assert.Equal(t, `      minikube    minikube   3y
+*     microk8s    microk8s   3y
+      kind-kind   KIND       3y
+`, out.String())
+}
+
+func TestSortByName(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.SortBy = "{.name}"
+
+	err := o.Command().Flags().Set("output", "custom-columns=NAME:.name")
+	require.NoError(t, err)
+
+	err = o.Print(wideClusterList())
+	require.NoError(t, err)
+	assert.Equal(t, "NAME\nkind-kind\nmicrok8s\nminikube\n", out.String())
+}
+
+func watchEvents(events ...watch.Event) watch.Interface {
+	w := watch.NewFakeWithChanSize(len(events), false)
+	for _, e := range events {
+		w.Action(e.Type, e.Object)
+	}
+	w.Stop()
+	return w
+}
+
+func TestPrintWatchTable(t *testing.T) {
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+	o.StartTime = startTime
+
+	list := wideClusterList()
+	unhealthy := list.Items[0]
+	unhealthy.Status.Error = "healthcheck: timeout"
+	w := watchEvents(
+		watch.Event{Type: watch.Added, Object: &list.Items[0]},
+		watch.Event{Type: watch.Added, Object: &list.Items[1]},
+		watch.Event{Type: watch.Error, Object: &metav1.Status{Message: "reading kubeconfig"}},
+		watch.Event{Type: watch.Modified, Object: &unhealthy},
+		watch.Event{Type: watch.Deleted, Object: &list.Items[1]},
+	)
+
+	err := o.PrintWatch(w, "")
+	require.NoError(t, err)
+	assert.Equal(t, `EVENT      CURRENT   NAME                   PRODUCT    AGE
+ADDED      *         microk8s               microk8s   3y
+ADDED                kind-kind              KIND       3y
+MODIFIED   *         microk8s               microk8s   3y
+DELETED              kind-kind              KIND       3y
+`, out.String())
+	assert.Equal(t, "Error: reading kubeconfig\n", errOut.String())
+}
+
+func TestPrintWatchName(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+
+	err := o.Command().Flags().Set("output", "custom-columns=NAME:.name,VERSION:.status.kubernetesVersion")
+	require.NoError(t, err)
+
+	list := wideClusterList()
+	w := watchEvents(
+		watch.Event{Type: watch.Added, Object: &list.Items[0]},
+		watch.Event{Type: watch.Added, Object: &list.Items[1]},
+		watch.Event{Type: watch.Deleted, Object: &list.Items[1]},
+	)
+
+	err = o.PrintWatch(w, "kind-kind")
+	require.NoError(t, err)
+	assert.Equal(t, "NAME        VERSION\nkind-kind   1.23.4\nkind-kind   1.23.4\n", out.String())
+}
+
+func TestPrintWatchJSON(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewGetOptions()
+	o.IOStreams = streams
+
+	err := o.Command().Flags().Set("output", "json")
+	require.NoError(t, err)
+
+	w := watchEvents(
+		watch.Event{Type: watch.Added, Object: &api.Cluster{TypeMeta: clusterType, Name: "kind-kind", Product: "kind"}},
+		watch.Event{Type: watch.Added, Object: &api.Cluster{TypeMeta: clusterType, Name: "minikube", Product: "minikube"}},
+		watch.Event{Type: watch.Deleted, Object: &api.Cluster{TypeMeta: clusterType, Name: "kind-kind", Product: "kind"}},
+	)
+
+	err = o.PrintWatch(w, "kind-kind")
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"ADDED","object":{"kind":"Cluster","apiVersion":"yap.pseudonator.io/v1alpha1","name":"kind-kind","product":"kind","status":{"creationTimestamp":null}}}
+{"type":"DELETED","object":{"kind":"Cluster","apiVersion":"yap.pseudonator.io/v1alpha1","name":"kind-kind","product":"kind","status":{"creationTimestamp":null}}}
+`, out.String())
+}
+
+func TestWithNameSelector(t *testing.T) {
+	assert.Equal(t, "", withNameSelector("", ""))
+	assert.Equal(t, "product=kind", withNameSelector("product=kind", ""))
+	assert.Equal(t, "name=kind-kind", withNameSelector("", "kind-kind"))
+	assert.Equal(t, "product=kind,name=kind-kind", withNameSelector("product=kind", "kind-kind"))
+	assert.Equal(t, `name=a\,b`, withNameSelector("", "a,b"))
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+// The actions that LifecycleOptions can take on a cluster.
+const (
+	lifecycleStop  = "stop"
+	lifecycleStart = "start"
+	lifecyclePause = "pause"
+)
+
+// LifecycleOptions backs `yap stop`, `yap start`, and `yap pause`,
+// which only differ in the action they take.
+type LifecycleOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+
+	action string
+}
+
+func newLifecycleOptions(action, operation string) *LifecycleOptions {
+	return &LifecycleOptions{
+		PrintFlags: genericclioptions.NewPrintFlags(operation),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+		action:     action,
+	}
+}
+
+func NewStopOptions() *LifecycleOptions {
+	return newLifecycleOptions(lifecycleStop, "stopped")
+}
+
+func NewStartOptions() *LifecycleOptions {
+	return newLifecycleOptions(lifecycleStart, "started")
+}
+
+func NewPauseOptions() *LifecycleOptions {
+	return newLifecycleOptions(lifecyclePause, "paused")
+}
+
+func (o *LifecycleOptions) Command() *cobra.Command {
+	var cmd *cobra.Command
+	switch o.action {
+	case lifecycleStop:
+		cmd = &cobra.Command{
+			Use:   "stop cluster [name]",
+			Short: "Stop a running cluster, keeping its state",
+			Long: `Stop a running cluster, keeping its state.
+
+Start it again with 'yap start cluster'.
+
+Supported for kind, k3d, minikube, and colima clusters.
+`,
+			Example: "  yap stop cluster kind-kind\n" +
+				"  yap stop cluster minikube",
+		}
+	case lifecycleStart:
+		cmd = &cobra.Command{
+			Use:   "start cluster [name]",
+			Short: "Start a stopped or paused cluster",
+			Long: `Start a stopped or paused cluster, and wait for it to respond.
+
+Supported for kind, k3d, minikube, and colima clusters.
+`,
+			Example: "  yap start cluster kind-kind\n" +
+				"  yap start cluster minikube",
+		}
+	case lifecyclePause:
+		cmd = &cobra.Command{
+			Use:   "pause cluster [name]",
+			Short: "Pause a running cluster, keeping it in memory",
+			Long: `Pause a running cluster, keeping it in memory.
+
+Resume it with 'yap start cluster'.
+
+Supported for kind and minikube clusters.
+`,
+			Example: "  yap pause cluster kind-kind\n" +
+				"  yap pause cluster minikube",
+		}
+	}
+	cmd.Run = o.Run
+	cmd.Args = cobra.ExactArgs(2)
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *LifecycleOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterLifecycle interface {
+	clusterGetter
+	Stop(ctx context.Context, name string) error
+	Start(ctx context.Context, name string) error
+	Pause(ctx context.Context, name string) error
+}
+
+func (o *LifecycleOptions) run(controller clusterLifecycle, args []string) error {
+	t := args[0]
+	if t != "cluster" && t != "clusters" {
+		return fmt.Errorf("Unrecognized type: %s. Possible values: cluster", t)
+	}
+
+	printer, err := toPrinter(o.PrintFlags)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	name := args[1]
+	existing, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	switch o.action {
+	case lifecycleStop:
+		err = controller.Stop(ctx, existing.Name)
+	case lifecycleStart:
+		err = controller.Start(ctx, existing.Name)
+	case lifecyclePause:
+		err = controller.Pause(ctx, existing.Name)
+	default:
+		err = fmt.Errorf("internal error: unknown action %s", o.action)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printer.PrintObj(&api.Cluster{
+		TypeMeta: cluster.TypeMeta(),
+		Name:     existing.Name,
+		Product:  existing.Product,
+	}, o.Out)
+}
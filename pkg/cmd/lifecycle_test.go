@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+func TestLifecycleCluster(t *testing.T) {
+	for _, tc := range []struct {
+		options  *LifecycleOptions
+		expected string
+		output   string
+	}{
+		{NewStopOptions(), "stop kind-kind", "cluster.yap.pseudonator.io/kind-kind stopped\n"},
+		{NewStartOptions(), "start kind-kind", "cluster.yap.pseudonator.io/kind-kind started\n"},
+		{NewPauseOptions(), "pause kind-kind", "cluster.yap.pseudonator.io/kind-kind paused\n"},
+	} {
+		t.Run(tc.expected, func(t *testing.T) {
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := tc.options
+			o.IOStreams = streams
+
+			cd := &fakeClusterController{
+				clusters: map[string]*api.Cluster{
+					"kind-kind": &api.Cluster{TypeMeta: cluster.TypeMeta(), Name: "kind-kind", Product: "kind"},
+				},
+			}
+
+			// Short product names resolve to the default cluster.
+			err := o.run(cd, []string{"cluster", "kind"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cd.lastLifecycle)
+			assert.Equal(t, tc.output, out.String())
+		})
+	}
+}
+
+func TestLifecycleClusterErrors(t *testing.T) {
+	for _, tc := range []struct {
+		args      []string
+		nextError error
+		expected  string
+	}{
+		{[]string{"registry", "microk8s"}, nil, "Unrecognized type: registry"},
+		{[]string{"cluster", "kind-kind"}, nil, `"kind-kind" not found`},
+		{[]string{"cluster", "microk8s"}, fmt.Errorf("cluster type microk8s does not support stop"), "does not support stop"},
+	} {
+		t.Run(fmt.Sprintf("%v", tc.args), func(t *testing.T) {
+			cd := &fakeClusterController{
+				clusters: map[string]*api.Cluster{
+					"microk8s": &api.Cluster{TypeMeta: cluster.TypeMeta(), Name: "microk8s"},
+				},
+				nextError: tc.nextError,
+			}
+
+			o := NewStopOptions()
+			o.IOStreams, _, _, _ = genericclioptions.NewTestIOStreams()
+			err := o.run(cd, tc.args)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.expected)
+			}
+			assert.Equal(t, "", cd.lastLifecycle)
+		})
+	}
+}
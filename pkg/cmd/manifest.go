@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/pseudonator/yap/pkg/encoding"
+	"github.com/pseudonator/yap/pkg/visitor"
+)
+
+// Flags for rewriting manifests before they're decoded,
+// shared by every command that reads -f.
+type ManifestFlags struct {
+	ExpandEnv   bool
+	ValuesFiles []string
+}
+
+func (f *ManifestFlags) AddFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&f.ExpandEnv, "expand-env", f.ExpandEnv,
+		"If true, expand ${VAR} and ${VAR:-default} references in the manifests from the environment. Use $${ for a literal ${")
+	flags.StringArrayVar(&f.ValuesFiles, "values", f.ValuesFiles,
+		"Render the manifests as Go templates, with values from this YAML file available as .Values. "+
+			"May be repeated; later files override earlier ones")
+}
+
+// Reads and decodes the objects in the files.
+func (f *ManifestFlags) decode(filenames []string, recursive bool, stdin io.Reader) ([]runtime.Object, error) {
+	opts, err := f.parseOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	visitors, err := visitor.FromStrings(filenames, stdin, recursive)
+	if err != nil {
+		return nil, err
+	}
+	return visitor.DecodeAllWithOptions(visitors, opts)
+}
+
+func (f *ManifestFlags) parseOptions() (encoding.ParseOptions, error) {
+	opts := encoding.ParseOptions{ExpandEnv: f.ExpandEnv}
+	if len(f.ValuesFiles) == 0 {
+		return opts, nil
+	}
+
+	values := map[string]interface{}{}
+	for _, path := range f.ValuesFiles {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return opts, errors.Wrap(err, "reading values")
+		}
+
+		fileValues := map[string]interface{}{}
+		err = yaml.Unmarshal(contents, &fileValues)
+		if err != nil {
+			return opts, errors.Wrapf(err, "reading values %s", path)
+		}
+		mergeValues(values, fileValues)
+	}
+	opts.Values = values
+	return opts, nil
+}
+
+// Merges src into dst. Maps merge key by key; everything else in src
+// replaces what's in dst.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := dst[k].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
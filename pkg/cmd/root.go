@@ -9,13 +9,19 @@ func NewRootCommand() *cobra.Command {
 		Use:   "yap [command]",
 		Short: "One provisioner to rule them all",
 		Example: "  yap get clusters\n" +
-			"  yap apply -f my-cluster.yaml",
+			"  yap apply -f my-cluster.yaml\n" +
+			"  yap wait cluster kind-kind --for=condition=Ready",
 	}
 
 	rootCmd.AddCommand(NewCreateOptions().Command())
 	rootCmd.AddCommand(NewGetOptions().Command())
 	rootCmd.AddCommand(NewApplyOptions().Command())
+	rootCmd.AddCommand(NewDiffOptions().Command())
 	rootCmd.AddCommand(NewDeleteOptions().Command())
+	rootCmd.AddCommand(NewWaitOptions().Command())
+	rootCmd.AddCommand(NewStopOptions().Command())
+	rootCmd.AddCommand(NewStartOptions().Command())
+	rootCmd.AddCommand(NewPauseOptions().Command())
 
 	return rootCmd
 }
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Returns a context that's canceled on Ctrl-C or SIGTERM.
+//
+// Commands that create or delete clusters use this so that an interrupt
+// stops the current step and gives them a chance to clean up, instead
+// of killing the process mid-create.
+//
+// We only catch the first signal. A second one gets the default behavior,
+// so users can still kill a cleanup that's taking too long.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/visitor"
+)
+
+// The directory where `--template NAME` looks for NAME.yaml, or empty if
+// we can't find a home directory.
+func defaultTemplateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".yap", "templates")
+}
+
+// Loads a cluster template.
+//
+// The ref may be a file, a URL, "-" for stdin, or the name of a
+// template in the template directory.
+func loadClusterTemplate(ref string, stdin io.Reader, templateDir string) (*api.ClusterTemplate, error) {
+	source := ref
+	name := ""
+	if !isTemplateSource(ref) {
+		if templateDir == "" {
+			return nil, fmt.Errorf("template %q not found", ref)
+		}
+		source = filepath.Join(templateDir, ref+".yaml")
+		name = ref
+		_, err := os.Stat(source)
+		if err != nil {
+			return nil, fmt.Errorf("template %q not found: expected a file, a URL, or %s", ref, source)
+		}
+	}
+
+	visitors, err := visitor.FromStrings([]string{source}, stdin, false)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := visitor.DecodeAll(visitors)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []*api.ClusterTemplate{}
+	for _, obj := range objs {
+		t, ok := obj.(*api.ClusterTemplate)
+		if !ok {
+			continue
+		}
+		if name != "" && t.Name != "" && t.Name != name {
+			continue
+		}
+		templates = append(templates, t)
+	}
+
+	switch len(templates) {
+	case 0:
+		return nil, fmt.Errorf("no ClusterTemplate found in %s", source)
+	case 1:
+		return templates[0], nil
+	}
+	return nil, fmt.Errorf("%s contains %d ClusterTemplates. Expected one", source, len(templates))
+}
+
+// Whether the ref points directly at a template, rather than naming one.
+func isTemplateSource(ref string) bool {
+	if ref == "-" || strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return true
+	}
+	_, err := os.Stat(ref)
+	return err == nil
+}
+
+// Parses --set NAME=VALUE flags.
+func parseTemplateValues(set []string) (map[string]string, error) {
+	values := make(map[string]string, len(set))
+	for _, s := range set {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --set %q. Expected NAME=VALUE", s)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Templates aren't clusters, so commands that take clusters reject them.
+func templateNotApplicableError(t *api.ClusterTemplate) error {
+	return fmt.Errorf("ClusterTemplate %s is not a cluster. Create a cluster from it with: yap create cluster --template %s",
+		t.Name, t.Name)
+}
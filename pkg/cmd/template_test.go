@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const teamKindTemplateYAML = `apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterTemplate
+name: team-kind
+parameters:
+- name: workers
+  default: "1"
+template:
+  product: kind
+  name: kind-team
+  kindV1Alpha4Cluster:
+    nodes:
+    - role: control-plane
+    - role: worker
+      yap.pseudonator.io/repeat: $(workers)
+`
+
+func writeTemplate(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "team-kind.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(teamKindTemplateYAML), 0644))
+	return path
+}
+
+func TestCreateClusterFromTemplate(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+	o.Template = writeTemplate(t, t.TempDir())
+	o.Set = []string{"workers=2"}
+
+	fcc := &fakeClusterController{}
+	err := o.run(context.Background(), fcc, "")
+	require.NoError(t, err)
+	assert.Equal(t, "cluster.yap.pseudonator.io/kind-team created\n", out.String())
+	assert.Equal(t, "kind-team", fcc.lastApplyName)
+
+	applied := fcc.clusters["kind-team"]
+	assert.Equal(t, "Cluster", applied.Kind)
+	assert.Equal(t, "kind", applied.Product)
+	assert.Nil(t, applied.Minikube)
+	assert.Equal(t, 3, len(applied.KindV1Alpha4Cluster.Nodes))
+}
+
+func TestCreateClusterFromTemplateFlagsOverride(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+	o.Template = writeTemplate(t, t.TempDir())
+	flags := o.Command().Flags()
+	require.NoError(t, flags.Set("name", "kind-other"))
+	require.NoError(t, flags.Set("min-cpus", "3"))
+
+	fcc := &fakeClusterController{}
+	err := o.run(context.Background(), fcc, "kind")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-other", fcc.lastApplyName)
+	assert.Equal(t, 3, fcc.clusters["kind-other"].MinCPUs)
+}
+
+func TestCreateClusterFromTemplateUnsetFlags(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+	o.Template = writeTemplate(t, t.TempDir())
+	o.Command()
+
+	// Values that didn't come from the command line, like flag
+	// defaults, never override the template.
+	o.Cluster.Name = "kind-default"
+
+	fcc := &fakeClusterController{}
+	err := o.run(context.Background(), fcc, "kind")
+	require.NoError(t, err)
+	assert.Equal(t, "kind-team", fcc.lastApplyName)
+}
+
+func TestCreateClusterFromTemplateErrors(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+	o.Template = writeTemplate(t, t.TempDir())
+
+	err := o.run(context.Background(), &fakeClusterController{}, "minikube")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "template team-kind creates kind clusters, not minikube")
+	}
+
+	o.Set = []string{"nodes=2"}
+	err = o.run(context.Background(), &fakeClusterController{}, "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "template team-kind has no parameter nodes. Parameters: workers")
+	}
+
+	o.Set = []string{"workers"}
+	err = o.run(context.Background(), &fakeClusterController{}, "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid --set "workers". Expected NAME=VALUE`)
+	}
+}
+
+func TestCreateClusterMissingProduct(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewCreateClusterOptions()
+	o.IOStreams = streams
+
+	err := o.run(context.Background(), &fakeClusterController{}, "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Missing product")
+	}
+}
+
+func TestLoadClusterTemplateByName(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir)
+
+	tmpl, err := loadClusterTemplate("team-kind", nil, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "team-kind", tmpl.Name)
+
+	_, err = loadClusterTemplate("team-k3d", nil, dir)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `template "team-k3d" not found`)
+	}
+}
+
+func TestApplyRejectsClusterTemplate(t *testing.T) {
+	streams, in, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewApplyOptions()
+	o.IOStreams = streams
+	o.Filenames = []string{"-"}
+	o.clusterApplierFactory = newFakeClusterAppliers().new
+	_, _ = in.Write([]byte(teamKindTemplateYAML))
+
+	err := o.run(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ClusterTemplate team-kind is not a cluster")
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+type WaitOptions struct {
+	*genericclioptions.PrintFlags
+	genericclioptions.IOStreams
+
+	For     string
+	Timeout time.Duration
+}
+
+func NewWaitOptions() *WaitOptions {
+	return &WaitOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("condition met"),
+		IOStreams:  genericclioptions.IOStreams{Out: os.Stdout, ErrOut: os.Stderr, In: os.Stdin},
+		For:        "condition=Ready",
+		Timeout:    5 * time.Minute,
+	}
+}
+
+func (o *WaitOptions) Command() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "wait cluster [name]",
+		Short: "Wait for a cluster to reach a condition",
+		Long: `Wait for a cluster to reach a condition.
+
+The Ready condition waits until the cluster can run workloads:
+the nodes are ready, DNS is available, and the default StorageClass
+and ServiceAccount exist.
+`,
+		Example: "  yap wait cluster kind-kind\n" +
+			"  yap wait cluster kind-kind --for=condition=Ready --timeout=5m\n" +
+			"  yap wait cluster microk8s --for=condition=NodesReady",
+		Run:  o.Run,
+		Args: cobra.ExactArgs(2),
+	}
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	o.PrintFlags.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.For, "for", o.For,
+		"The condition to wait on: condition=NAME. One of Ready, APIServerReachable, NodesReady, SpecRecorded, MachineHealthy.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout,
+		"The length of time to wait before giving up.")
+
+	return cmd
+}
+
+func (o *WaitOptions) Run(cmd *cobra.Command, args []string) {
+	controller, err := cluster.DefaultController(o.IOStreams)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+
+	err = o.run(controller, args)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+type clusterWaiter interface {
+	clusterGetter
+	WaitForCondition(ctx context.Context, name string, t api.ClusterConditionType, timeout time.Duration) (*api.Cluster, error)
+}
+
+func (o *WaitOptions) run(controller clusterWaiter, args []string) error {
+	t := args[0]
+	if t != "cluster" && t != "clusters" {
+		return fmt.Errorf("Unrecognized type: %s. Possible values: cluster", t)
+	}
+
+	conditionName, ok := strings.CutPrefix(o.For, "condition=")
+	if !ok {
+		return fmt.Errorf("Invalid --for: %s. Expected condition=NAME", o.For)
+	}
+	conditionType, err := cluster.ParseConditionType(conditionName)
+	if err != nil {
+		return err
+	}
+
+	printer, err := toPrinter(o.PrintFlags)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	name := args[1]
+	existing, err := normalizedGet(ctx, controller, name)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.WaitForCondition(ctx, existing.Name, conditionType, o.Timeout)
+	if err != nil {
+		return err
+	}
+	return printer.PrintObj(result, o.Out)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/cluster"
+)
+
+func TestWaitCluster(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewWaitOptions()
+	o.IOStreams = streams
+
+	cd := &fakeClusterController{
+		clusters: map[string]*api.Cluster{
+			"kind-kind": &api.Cluster{TypeMeta: cluster.TypeMeta(), Name: "kind-kind"},
+		},
+	}
+
+	// Short product names resolve to the default cluster.
+	err := o.run(cd, []string{"cluster", "kind"})
+	require.NoError(t, err)
+	assert.Equal(t, "cluster.yap.pseudonator.io/kind-kind condition met\n", out.String())
+	assert.Equal(t, "kind-kind Ready 5m0s", cd.lastWait)
+}
+
+func TestWaitClusterCondition(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewWaitOptions()
+	o.IOStreams = streams
+
+	cd := &fakeClusterController{
+		clusters: map[string]*api.Cluster{
+			"microk8s": &api.Cluster{TypeMeta: cluster.TypeMeta(), Name: "microk8s"},
+		},
+	}
+
+	cmd := o.Command()
+	require.NoError(t, cmd.Flags().Set("for", "condition=nodesready"))
+	require.NoError(t, cmd.Flags().Set("timeout", "30s"))
+
+	err := o.run(cd, []string{"cluster", "microk8s"})
+	require.NoError(t, err)
+	assert.Equal(t, "microk8s NodesReady 30s", cd.lastWait)
+}
+
+func TestWaitClusterErrors(t *testing.T) {
+	cd := &fakeClusterController{
+		clusters: map[string]*api.Cluster{
+			"microk8s": &api.Cluster{TypeMeta: cluster.TypeMeta(), Name: "microk8s"},
+		},
+	}
+
+	for _, tc := range []struct {
+		args     []string
+		forFlag  string
+		expected string
+	}{
+		{[]string{"registry", "microk8s"}, "condition=Ready", "Unrecognized type: registry"},
+		{[]string{"cluster", "microk8s"}, "Ready", "Invalid --for: Ready. Expected condition=NAME"},
+		{[]string{"cluster", "microk8s"}, "condition=Happy", `unknown condition "Happy"`},
+		{[]string{"cluster", "kind-kind"}, "condition=Ready", `"kind-kind" not found`},
+	} {
+		t.Run(fmt.Sprintf("%v %s", tc.args, tc.forFlag), func(t *testing.T) {
+			o := NewWaitOptions()
+			o.IOStreams, _, _, _ = genericclioptions.NewTestIOStreams()
+			o.For = tc.forFlag
+			err := o.run(cd, tc.args)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.expected)
+			}
+		})
+	}
+}
@@ -1,50 +1,167 @@
 package encoding
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
+	"reflect"
 
-	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/pseudonator/yap/pkg/api"
 )
 
-// Parses a stream of YAML.
+// Parses a stream of YAML or JSON.
+//
+// The stream may contain many documents. A document may be a single object,
+// or a list of objects, like a JSON array. Errors include the document and
+// the line where decoding failed.
 func ParseStream(r io.Reader) ([]runtime.Object, error) {
-	var current bytes.Buffer
-	reader := io.TeeReader(bufio.NewReader(r), &current)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
 
-	objDecoder := yaml.NewDecoder(&current)
-	objDecoder.KnownFields(true)
+	docs, err := parseDocuments(data)
+	if err != nil {
+		return nil, err
+	}
 
-	typeDecoder := yaml.NewDecoder(reader)
 	result := []runtime.Object{}
-	for {
-		tm := api.TypeMeta{}
-		if err := typeDecoder.Decode(&tm); err != nil {
-			if err == io.EOF {
-				break
+	for i, doc := range docs {
+		items := []*yaml.Node{doc}
+		if doc.Kind == yaml.SequenceNode {
+			items = doc.Content
+		}
+
+		for _, item := range items {
+			obj, err := decodeObject(i+1, item)
+			if err != nil {
+				return nil, err
 			}
-			return nil, err
+
+			list, ok := obj.(*api.ClusterList)
+			if !ok {
+				result = append(result, obj)
+				continue
+			}
+
+			listObjs, err := listItems(list)
+			if err != nil {
+				return nil, &PositionError{Document: i + 1, Line: item.Line, Column: item.Column, Err: err}
+			}
+			result = append(result, listObjs...)
 		}
+	}
+	return result, nil
+}
+
+// Splits the stream into the root nodes of its documents,
+// skipping empty documents.
+func parseDocuments(data []byte) ([]*yaml.Node, error) {
+	// YAML can't parse a stream of JSON values, like `{...}{...}`,
+	// so we split JSON with a JSON parser first.
+	docs, ok := jsonDocuments(data)
+	if ok {
+		return docs, nil
+	}
 
-		obj, err := determineObj(tm)
+	docs = []*yaml.Node{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		err := decoder.Decode(doc)
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return nil, err
+			return nil, syntaxError(data, err)
 		}
 
-		if err := objDecoder.Decode(obj); err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, errors.Wrapf(err, "decoding %s", tm)
+		root := documentRoot(doc)
+		if root == nil {
+			continue
+		}
+		docs = append(docs, root)
+	}
+	return docs, nil
+}
+
+// Decodes a single object, strictly.
+func decodeObject(doc int, node *yaml.Node) (runtime.Object, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, &PositionError{Document: doc, Line: node.Line, Column: node.Column,
+			Err: fmt.Errorf("expected an object with apiVersion and kind, found %s", describeNode(node))}
+	}
+
+	tm := api.TypeMeta{}
+	err := node.Decode(&tm)
+	if err != nil {
+		return nil, typeError(doc, node, err)
+	}
+
+	obj, err := determineObj(tm)
+	if err != nil {
+		return nil, &PositionError{Document: doc, Line: node.Line, Column: node.Column, Err: err}
+	}
+
+	key, keyType := unknownField(node, reflect.TypeOf(obj))
+	if key != nil {
+		return nil, &PositionError{Document: doc, Line: key.Line, Column: key.Column,
+			Err: fmt.Errorf("field %s not found in type %s", key.Value, keyType)}
+	}
+
+	err = node.Decode(obj)
+	if err != nil {
+		return nil, typeError(doc, node, err)
+	}
+
+	cluster, ok := obj.(*api.Cluster)
+	if ok {
+		err = moveMetadataLabels(cluster)
+		if err != nil {
+			return nil, &PositionError{Document: doc, Line: node.Line, Column: node.Column, Err: err}
+		}
+	}
+	return obj, nil
+}
+
+// Moves metadata.labels to labels, so that the rest of yap only
+// has to look in one place.
+func moveMetadataLabels(cluster *api.Cluster) error {
+	if cluster.Metadata == nil {
+		return nil
+	}
+	if len(cluster.Metadata.Labels) > 0 {
+		if len(cluster.Labels) > 0 {
+			return fmt.Errorf("cluster sets both labels and metadata.labels. Set only one")
 		}
+		cluster.Labels = cluster.Metadata.Labels
+	}
+	cluster.Metadata = nil
+	return nil
+}
 
-		result = append(result, obj)
+// Flattens a ClusterList into its Clusters, so that callers never
+// have to handle lists.
+func listItems(list *api.ClusterList) ([]runtime.Object, error) {
+	result := []runtime.Object{}
+	for i := range list.Items {
+		item := list.Items[i]
+		if item.Kind != "" && item.Kind != "Cluster" {
+			return nil, fmt.Errorf("ClusterList items must have `kind: Cluster`, found item %d with `kind: %s`", i, item.Kind)
+		}
+		if item.APIVersion != "" && item.APIVersion != list.APIVersion {
+			return nil, fmt.Errorf("ClusterList items must have `apiVersion: %s`, found item %d with `apiVersion: %s`",
+				list.APIVersion, i, item.APIVersion)
+		}
+		err := moveMetadataLabels(&item)
+		if err != nil {
+			return nil, fmt.Errorf("ClusterList item %d: %v", i, err)
+		}
+		item.TypeMeta = api.TypeMeta{Kind: "Cluster", APIVersion: list.APIVersion}
+		result = append(result, &item)
 	}
 	return result, nil
 }
@@ -56,8 +173,14 @@ func determineObj(tm api.TypeMeta) (runtime.Object, error) {
 		switch tm.Kind {
 		case "Cluster":
 			return &api.Cluster{}, nil
+		case "ClusterList":
+			return &api.ClusterList{}, nil
+		case "ClusterTemplate":
+			return &api.ClusterTemplate{}, nil
+		case "Registry":
+			return &api.Registry{}, nil
 		default:
-			return nil, fmt.Errorf("yap config must contain: `kind: Cluster`")
+			return nil, fmt.Errorf("yap config must contain: `kind: Cluster`, `kind: ClusterList`, `kind: ClusterTemplate`, or `kind: Registry`")
 		}
 	default:
 		return nil, fmt.Errorf("yap config must contain: `apiVersion: aap.pseudonator.io/v1alpha1`")
@@ -38,7 +38,7 @@ product: microk8s
 `
 	_, err := ParseStream(strings.NewReader(yaml))
 	if assert.Error(t, err) {
-		assert.Contains(t, err.Error(), "decoding {Cluster yap.pseudonator.io/v1alpha1}: yaml: unmarshal errors:\n  line 4: field nameTypo not found in type api.Cluster")
+		assert.Contains(t, err.Error(), "document 1, line 4, column 1: field nameTypo not found in type api.Cluster")
 	}
 }
 
@@ -56,6 +56,184 @@ product: microk8s
 `
 	_, err := ParseStream(strings.NewReader(yaml))
 	if assert.Error(t, err) {
-		assert.Contains(t, err.Error(), "decoding {Cluster yap.pseudonator.io/v1alpha1}: yaml: unmarshal errors:\n  line 9: field nameTypo not found in type api.Cluster")
+		assert.Contains(t, err.Error(), "document 2, line 9, column 1: field nameTypo not found in type api.Cluster")
+	}
+}
+
+func TestParseRegistry(t *testing.T) {
+	yaml := `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: Registry
+name: kind-registry
+port: 5001
+---
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+registry: kind-registry
+`
+	data, err := ParseStream(strings.NewReader(yaml))
+	assert.NoError(t, err)
+	require.Equal(t, 2, len(data))
+	assert.Equal(t, "kind-registry", data[0].(*api.Registry).Name)
+	assert.Equal(t, 5001, data[0].(*api.Registry).Port)
+	assert.Equal(t, "kind-registry", data[1].(*api.Cluster).Registry)
+}
+
+func TestParseClusterList(t *testing.T) {
+	yaml := `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterList
+items:
+- product: kind
+- apiVersion: yap.pseudonator.io/v1alpha1
+  kind: Cluster
+  product: k3d
+---
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: minikube
+`
+	data, err := ParseStream(strings.NewReader(yaml))
+	assert.NoError(t, err)
+	require.Equal(t, 3, len(data))
+	assert.Equal(t, "kind", data[0].(*api.Cluster).Product)
+	assert.Equal(t, "Cluster", data[0].(*api.Cluster).Kind)
+	assert.Equal(t, "k3d", data[1].(*api.Cluster).Product)
+	assert.Equal(t, "minikube", data[2].(*api.Cluster).Product)
+}
+
+func TestParseMetadataLabels(t *testing.T) {
+	yaml := `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+metadata:
+  labels:
+    team: payments
+---
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterList
+items:
+- product: k3d
+  metadata:
+    labels:
+      ephemeral: "true"
+`
+	data, err := ParseStream(strings.NewReader(yaml))
+	assert.NoError(t, err)
+	require.Equal(t, 2, len(data))
+	assert.Equal(t, map[string]string{"team": "payments"}, data[0].(*api.Cluster).Labels)
+	assert.Nil(t, data[0].(*api.Cluster).Metadata)
+	assert.Equal(t, map[string]string{"ephemeral": "true"}, data[1].(*api.Cluster).Labels)
+}
+
+func TestParseMetadataLabelsConflict(t *testing.T) {
+	yaml := `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+labels:
+  team: payments
+metadata:
+  labels:
+    team: search
+`
+	_, err := ParseStream(strings.NewReader(yaml))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "document 1, line 2, column 1: cluster sets both labels and metadata.labels")
+	}
+}
+
+func TestParseClusterListWrongKind(t *testing.T) {
+	yaml := `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterList
+items:
+- kind: Registry
+  product: kind
+`
+	_, err := ParseStream(strings.NewReader(yaml))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ClusterList items must have `kind: Cluster`, found item 0 with `kind: Registry`")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	json := `{"apiVersion": "yap.pseudonator.io/v1alpha1", "kind": "Cluster", "product": "kind", "minCPUs": 2}
+{"apiVersion": "yap.pseudonator.io/v1alpha1", "kind": "Registry", "name": "yap-registry"}
+`
+	data, err := ParseStream(strings.NewReader(json))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(data))
+	assert.Equal(t, 2, data[0].(*api.Cluster).MinCPUs)
+	assert.Equal(t, "yap-registry", data[1].(*api.Registry).Name)
+}
+
+func TestParseJSONArray(t *testing.T) {
+	json := `[
+  {"apiVersion": "yap.pseudonator.io/v1alpha1", "kind": "Cluster", "product": "kind"},
+  {"apiVersion": "yap.pseudonator.io/v1alpha1", "kind": "Cluster", "product": "k3d"}
+]`
+	data, err := ParseStream(strings.NewReader(json))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(data))
+	assert.Equal(t, "kind", data[0].(*api.Cluster).Product)
+	assert.Equal(t, "k3d", data[1].(*api.Cluster).Product)
+}
+
+func TestParseJSONTypo(t *testing.T) {
+	json := `{"apiVersion": "yap.pseudonator.io/v1alpha1", "kind": "Cluster", "product": "kind"}
+{"apiVersion": "yap.pseudonator.io/v1alpha1", "kind": "Cluster", "nameTypo": "kind"}`
+	_, err := ParseStream(strings.NewReader(json))
+	if assert.Error(t, err) {
+		assert.Equal(t, "document 2, line 2, column 66: field nameTypo not found in type api.Cluster", err.Error())
+	}
+}
+
+func TestParseNestedTypo(t *testing.T) {
+	yaml := `apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+kindV1Alpha4Cluster:
+  nodes:
+  - role: control-plane
+    imageTypo: kindest/node
+`
+	_, err := ParseStream(strings.NewReader(yaml))
+	if assert.Error(t, err) {
+		assert.Equal(t, "document 1, line 7, column 5: field imageTypo not found in type v1alpha4.Node", err.Error())
+	}
+}
+
+func TestParseTypeMismatch(t *testing.T) {
+	yaml := `apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+minCPUs: lots
+`
+	_, err := ParseStream(strings.NewReader(yaml))
+	if assert.Error(t, err) {
+		assert.Equal(t, "document 1, line 4, column 10: cannot unmarshal !!str `lots` into int", err.Error())
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	yaml := `apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+---
+product: kind
+  name: oops
+`
+	_, err := ParseStream(strings.NewReader(yaml))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "document 2, line 5: ")
+	}
+}
+
+func TestParseNotAnObject(t *testing.T) {
+	_, err := ParseStream(strings.NewReader("- kind\n- k3d\n"))
+	if assert.Error(t, err) {
+		assert.Equal(t, "document 1, line 1, column 3: expected an object with apiVersion and kind, found a string", err.Error())
 	}
 }
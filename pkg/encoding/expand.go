@@ -0,0 +1,194 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Options for rewriting a stream before it's decoded.
+//
+// Variables are expanded first, then the values template is rendered.
+// A variable whose value contains {{ or a newline goes through the template
+// as a string literal, so the template never executes it, and errors from
+// both steps still point at lines in the original stream.
+type ParseOptions struct {
+	// When set, expand ${VAR} and ${VAR:-default} references.
+	// $${ is an escape for a literal ${.
+	ExpandEnv bool
+
+	// Looks up variables for ExpandEnv. Defaults to os.LookupEnv.
+	LookupEnv func(name string) (string, bool)
+
+	// When non-nil, render the stream as a Go template,
+	// with these values available as .Values.
+	Values map[string]interface{}
+}
+
+// Parses a stream of YAML, after rewriting it as the options ask.
+func ParseStreamWithOptions(r io.Reader, opts ParseOptions) ([]runtime.Object, error) {
+	if !opts.ExpandEnv && opts.Values == nil {
+		return ParseStream(r)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = Preprocess(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStream(bytes.NewReader(data))
+}
+
+// Rewrites a stream as the options ask, without decoding it.
+func Preprocess(data []byte, opts ParseOptions) ([]byte, error) {
+	var err error
+	if opts.ExpandEnv {
+		lookup := opts.LookupEnv
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		quote := func(value string) string { return value }
+		if opts.Values != nil {
+			quote = templateLiteral
+		}
+		data, err = expandEnv(data, lookup, quote)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Values != nil {
+		data, err = RenderValues(data, opts.Values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Matches, in order: an escape, a well-formed reference, and anything
+// else that starts like a reference.
+var envRefRE = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:-)([^}]*))?\}|\$\{[^}]*\}?`)
+
+// Expands ${VAR} and ${VAR:-default} references.
+//
+// It's an error to reference a variable that isn't set and has no default,
+// so that a missing variable can't silently become an empty port or host.
+// Comment lines are left alone.
+func ExpandEnv(data []byte, lookup func(name string) (string, bool)) ([]byte, error) {
+	return expandEnv(data, lookup, func(value string) string { return value })
+}
+
+// Like ExpandEnv, but passes the value of each variable through quote.
+func expandEnv(data []byte, lookup func(name string) (string, bool), quote func(value string) string) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	var out strings.Builder
+	out.Grow(len(data))
+	for i, line := range lines {
+		if !strings.Contains(line, "${") || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			out.WriteString(line)
+			continue
+		}
+
+		var lineErr error
+		expanded := replaceAllSubmatchIndex(envRefRE, line, func(m []int) string {
+			ref := line[m[0]:m[1]]
+			if ref == "$${" {
+				return "${"
+			}
+			if lineErr != nil {
+				return ref
+			}
+			if m[2] < 0 {
+				lineErr = positionError(data, i+1, m[0]+1,
+					fmt.Errorf("invalid variable reference %q. Expected ${VAR} or ${VAR:-default}", strings.TrimRight(ref, "\r\n")))
+				return ref
+			}
+
+			name := line[m[2]:m[3]]
+			value, ok := lookup(name)
+			if m[4] >= 0 {
+				if !ok || value == "" {
+					return line[m[6]:m[7]]
+				}
+				return quote(value)
+			}
+			if !ok {
+				lineErr = positionError(data, i+1, m[0]+1,
+					fmt.Errorf("variable %s is not set. Set it, or give it a default with ${%s:-default}", name, name))
+				return ref
+			}
+			return quote(value)
+		})
+		if lineErr != nil {
+			return nil, lineErr
+		}
+		out.WriteString(expanded)
+	}
+	return []byte(out.String()), nil
+}
+
+// Wraps a value that the values template would misread in an action
+// that prints it verbatim. Quoting also escapes newlines, so the lines
+// after it keep their numbers.
+func templateLiteral(value string) string {
+	if !strings.Contains(value, "{{") && !strings.Contains(value, "\n") {
+		return value
+	}
+	return "{{" + strconv.Quote(value) + "}}"
+}
+
+// Like Regexp.ReplaceAllStringFunc, but passes the submatch indices.
+func replaceAllSubmatchIndex(re *regexp.Regexp, s string, repl func(m []int) string) string {
+	var out strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		out.WriteString(s[last:m[0]])
+		out.WriteString(repl(m))
+		last = m[1]
+	}
+	out.WriteString(s[last:])
+	return out.String()
+}
+
+// Matches the position that text/template puts at the start of its errors.
+var templateErrRE = regexp.MustCompile(`(?s)^template: manifest:(\d+)(?::(\d+))?: (?:executing "manifest" )?(.*)$`)
+
+// Renders the stream as a Go template, with the values available as .Values.
+//
+// Referencing a value that doesn't exist is an error.
+func RenderValues(data []byte, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New("manifest").Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, templateError(data, err)
+	}
+
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, map[string]interface{}{"Values": values})
+	if err != nil {
+		return nil, templateError(data, err)
+	}
+	return out.Bytes(), nil
+}
+
+// Converts a text/template error into a PositionError, if it has a position.
+func templateError(data []byte, err error) error {
+	match := templateErrRE.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(match[1])
+	col, _ := strconv.Atoi(match[2])
+	return positionError(data, line, col, fmt.Errorf("%s", match[3]))
+}
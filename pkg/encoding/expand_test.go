@@ -0,0 +1,128 @@
+package encoding
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+func fakeEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	out, err := ExpandEnv([]byte(`name: ${NAME}
+registry: ${REGISTRY:-yap-registry}
+host: ${HOST:-localhost}:${PORT}
+literal: $${NAME}
+# ${UNSET} in a comment
+`), fakeEnv(map[string]string{"NAME": "kind-dev", "PORT": "5001", "HOST": ""}))
+	require.NoError(t, err)
+	assert.Equal(t, `name: kind-dev
+registry: yap-registry
+host: localhost:5001
+literal: ${NAME}
+# ${UNSET} in a comment
+`, string(out))
+}
+
+func TestExpandEnvUnset(t *testing.T) {
+	_, err := ExpandEnv([]byte(`product: kind
+---
+product: k3d
+name: ${NAME}
+`), fakeEnv(nil))
+	if assert.Error(t, err) {
+		assert.Equal(t, "document 2, line 4, column 7: variable NAME is not set. "+
+			"Set it, or give it a default with ${NAME:-default}", err.Error())
+
+		var posErr *PositionError
+		require.True(t, errors.As(err, &posErr))
+		assert.Equal(t, 2, posErr.Document)
+	}
+}
+
+func TestExpandEnvInvalid(t *testing.T) {
+	_, err := ExpandEnv([]byte("name: ${NAME\n"), fakeEnv(nil))
+	if assert.Error(t, err) {
+		assert.Equal(t, `document 1, line 1, column 7: invalid variable reference "${NAME". `+
+			"Expected ${VAR} or ${VAR:-default}", err.Error())
+	}
+}
+
+func TestRenderValues(t *testing.T) {
+	objs, err := ParseStreamWithOptions(strings.NewReader(`apiVersion: yap.pseudonator.io/v1alpha1
+kind: Cluster
+product: kind
+name: {{ .Values.name }}
+registry: ${REGISTRY:-yap-registry}
+minCPUs: {{ .Values.resources.cpus }}
+`), ParseOptions{
+		ExpandEnv: true,
+		LookupEnv: fakeEnv(nil),
+		Values: map[string]interface{}{
+			"name":      "kind-dev",
+			"resources": map[string]interface{}{"cpus": 4},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(objs))
+
+	cluster := objs[0].(*api.Cluster)
+	assert.Equal(t, "kind-dev", cluster.Name)
+	assert.Equal(t, "yap-registry", cluster.Registry)
+	assert.Equal(t, 4, cluster.MinCPUs)
+}
+
+func TestRenderValuesMissing(t *testing.T) {
+	_, err := RenderValues([]byte(`product: kind
+---
+product: k3d
+name: {{ .Values.nmae }}
+`), map[string]interface{}{"name": "k3d-dev"})
+	if assert.Error(t, err) {
+		assert.Equal(t, `document 2, line 4, column 16: at <.Values.nmae>: map has no entry for key "nmae"`, err.Error())
+	}
+}
+
+func TestRenderValuesParseError(t *testing.T) {
+	_, err := RenderValues([]byte("---\nproduct: kind\nname: {{ .Values.name }\n"), map[string]interface{}{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "document 1, line 3: ")
+	}
+}
+
+func TestRenderValuesExpandedTemplateSyntax(t *testing.T) {
+	data, err := Preprocess([]byte(`product: kind
+name: "${NAME}"
+labels:
+  note: "${NOTE}"
+`), ParseOptions{
+		ExpandEnv: true,
+		LookupEnv: fakeEnv(map[string]string{"NAME": "{{ .Values.secret }}", "NOTE": "line 1\nline 2"}),
+		Values:    map[string]interface{}{"secret": "hunter2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "product: kind\nname: \"{{ .Values.secret }}\"\nlabels:\n  note: \"line 1\nline 2\"\n", string(data))
+
+	// A multi-line value doesn't move the lines after it.
+	_, err = Preprocess([]byte(`product: kind
+note: "${NOTE}"
+name: {{ .Values.nmae }}
+`), ParseOptions{
+		ExpandEnv: true,
+		LookupEnv: fakeEnv(map[string]string{"NOTE": "line 1\nline 2"}),
+		Values:    map[string]interface{}{},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "document 1, line 3, ")
+	}
+}
@@ -0,0 +1,224 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A problem at a position in a YAML stream.
+type PositionError struct {
+	// The index of the document in the stream, starting at 1.
+	Document int
+
+	// The line in the stream, starting at 1.
+	Line int
+
+	// The column in the line, starting at 1, or 0 if unknown.
+	Column int
+
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	pos := fmt.Sprintf("document %d, line %d", e.Document, e.Line)
+	if e.Column > 0 {
+		pos += fmt.Sprintf(", column %d", e.Column)
+	}
+	return fmt.Sprintf("%s: %v", pos, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+func positionError(data []byte, line, col int, err error) error {
+	return &PositionError{Document: documentAt(data, line), Line: line, Column: col, Err: err}
+}
+
+// Returns the index of the document that contains the line,
+// counting from 1.
+func documentAt(data []byte, line int) int {
+	doc := 1
+	hasContent := false
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < line-1 && i < len(lines); i++ {
+		l := strings.TrimRight(lines[i], " \t\r")
+		if l == "---" || strings.HasPrefix(l, "--- ") || strings.HasPrefix(l, "---\t") {
+			// A separator before any content starts the first document.
+			if hasContent {
+				doc++
+			}
+			rest := strings.TrimSpace(l[3:])
+			hasContent = rest != "" && !strings.HasPrefix(rest, "#")
+			continue
+		}
+		if l != "" && !strings.HasPrefix(strings.TrimSpace(l), "#") && !strings.HasPrefix(l, "%") {
+			hasContent = true
+		}
+	}
+	return doc
+}
+
+// Returns the line and column of a byte offset, counting from 1.
+func offsetPosition(data []byte, offset int) (int, int) {
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// Splits a stream of JSON values into nodes, with positions in the stream.
+//
+// Returns false if the stream isn't JSON, so that the caller can parse it
+// as YAML and report YAML errors.
+func jsonDocuments(data []byte) ([]*yaml.Node, bool) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+
+	docs := []*yaml.Node{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+
+		doc := &yaml.Node{}
+		err = yaml.Unmarshal(raw, doc)
+		if err != nil {
+			return nil, false
+		}
+
+		end := int(decoder.InputOffset())
+		line, col := offsetPosition(data, end-len(raw))
+		shiftNode(doc, line-1, col-1)
+
+		root := documentRoot(doc)
+		if root != nil {
+			docs = append(docs, root)
+		}
+	}
+	return docs, true
+}
+
+// Moves a node parsed on its own to where it starts in the stream.
+func shiftNode(node *yaml.Node, lines, cols int) {
+	if node.Line == 1 {
+		node.Column += cols
+	}
+	node.Line += lines
+	for _, child := range node.Content {
+		shiftNode(child, lines, cols)
+	}
+}
+
+// Returns the content of a document, or nil if it's empty.
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" && root.Value == "" {
+		return nil
+	}
+	return root
+}
+
+// Describes a node that should have been an object.
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.AliasNode:
+		return "an alias"
+	}
+	switch node.ShortTag() {
+	case "!!int", "!!float":
+		return "a number"
+	case "!!bool":
+		return "a boolean"
+	case "!!null":
+		return "null"
+	}
+	return "a string"
+}
+
+var yamlLineRE = regexp.MustCompile(`(?s)^(?:yaml: )?line (\d+): (.*)$`)
+
+// Converts a YAML syntax error into a PositionError.
+func syntaxError(data []byte, err error) error {
+	match := yamlLineRE.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(match[1])
+	return positionError(data, line, 0, errors.New(match[2]))
+}
+
+// Matches the value that yaml.v3 couldn't decode, like !!str `abc`.
+var typeErrorValueRE = regexp.MustCompile("`(.*)`")
+
+// Converts an error from decoding a node into a PositionError
+// at the first value that failed.
+func typeError(doc int, node *yaml.Node, err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) || len(typeErr.Errors) == 0 {
+		return &PositionError{Document: doc, Line: node.Line, Column: node.Column, Err: err}
+	}
+
+	msg := typeErr.Errors[0]
+	match := yamlLineRE.FindStringSubmatch(msg)
+	if match == nil {
+		return &PositionError{Document: doc, Line: node.Line, Column: node.Column, Err: errors.New(msg)}
+	}
+	line, _ := strconv.Atoi(match[1])
+	msg = match[2]
+	if len(typeErr.Errors) > 1 {
+		msg = fmt.Sprintf("%s (and %d more errors)", msg, len(typeErr.Errors)-1)
+	}
+
+	value := ""
+	valueMatch := typeErrorValueRE.FindStringSubmatch(match[2])
+	if valueMatch != nil {
+		value = valueMatch[1]
+	}
+	col := 0
+	found := findNode(node, func(n *yaml.Node) bool {
+		return n.Line == line && n.Kind == yaml.ScalarNode && n.Value == value
+	})
+	if found == nil {
+		found = findNode(node, func(n *yaml.Node) bool { return n.Line == line })
+	}
+	if found != nil {
+		col = found.Column
+	}
+	return &PositionError{Document: doc, Line: line, Column: col, Err: errors.New(msg)}
+}
+
+// Returns the first node in document order that matches.
+func findNode(node *yaml.Node, match func(n *yaml.Node) bool) *yaml.Node {
+	if match(node) {
+		return node
+	}
+	for _, child := range node.Content {
+		found := findNode(child, match)
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
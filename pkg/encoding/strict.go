@@ -0,0 +1,144 @@
+package encoding
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlUnmarshalerType     = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	obsoleteUnmarshalerType = reflect.TypeOf((*interface {
+		UnmarshalYAML(unmarshal func(interface{}) error) error
+	})(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Finds the first key in the node that isn't a field of the Go type.
+//
+// This is the same check as yaml.Decoder.KnownFields, but Node.Decode
+// can't do it, and we need the node to report where the key is.
+// Returns the key and the type that doesn't have it, or nil if every
+// key is known.
+func unknownField(node *yaml.Node, t reflect.Type) (*yaml.Node, reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if hasCustomUnmarshaler(t) {
+		return nil, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+			ft, ok := fields.byName[key.Value]
+			if !ok {
+				if fields.inlineMap == nil {
+					return key, t
+				}
+				ft = fields.inlineMap
+			}
+			bad, badType := unknownField(value, ft)
+			if bad != nil {
+				return bad, badType
+			}
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			bad, badType := unknownField(node.Content[i+1], t.Elem())
+			if bad != nil {
+				return bad, badType
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return nil, nil
+		}
+		for _, item := range node.Content {
+			bad, badType := unknownField(item, t.Elem())
+			if bad != nil {
+				return bad, badType
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Types that decode themselves, so we can't know their fields.
+func hasCustomUnmarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+	pt := reflect.PointerTo(t)
+	return pt.Implements(yamlUnmarshalerType) ||
+		pt.Implements(obsoleteUnmarshalerType) ||
+		pt.Implements(textUnmarshalerType)
+}
+
+type structFields struct {
+	byName map[string]reflect.Type
+
+	// The value type of an inline map, which accepts any key.
+	inlineMap reflect.Type
+}
+
+// Lists the YAML keys of a struct, following the same rules as yaml.v3.
+func yamlFields(t reflect.Type) structFields {
+	result := structFields{byName: make(map[string]reflect.Type)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("yaml")
+		if tag == "" && !strings.Contains(string(field.Tag), ":") {
+			tag = string(field.Tag)
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",inline,") {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Map:
+				result.inlineMap = ft.Elem()
+			case reflect.Struct:
+				inline := yamlFields(ft)
+				for k, v := range inline.byName {
+					result.byName[k] = v
+				}
+				if inline.inlineMap != nil {
+					result.inlineMap = inline.inlineMap
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		result.byName[name] = field.Type
+	}
+	return result
+}
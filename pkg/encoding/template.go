@@ -0,0 +1,246 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// A list item with this key is repeated that many times.
+const templateRepeatKey = "yap.pseudonator.io/repeat"
+
+// Matches $(NAME) parameter references, and $$( escapes.
+var templateRefRE = regexp.MustCompile(`\$\$\(|\$\(([A-Za-z_][A-Za-z0-9_-]*)\)`)
+
+// RenderClusterTemplate fills in the template's parameters and decodes
+// the result into a Cluster.
+//
+// Values override the parameter defaults. It's an error to set a parameter
+// that the template doesn't declare, or to leave a required parameter unset.
+func RenderClusterTemplate(t *api.ClusterTemplate, values map[string]string) (*api.Cluster, error) {
+	params, err := templateParams(t, values)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &yaml.Node{}
+	err = body.Encode(t.Template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering template %s", t.Name)
+	}
+
+	r := templateRenderer{params: params}
+	err = r.render(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering template %s", t.Name)
+	}
+
+	// Round-trip through YAML, so that we can reject unknown fields
+	// the same way ParseStream does.
+	data, err := yaml.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering template %s", t.Name)
+	}
+
+	cluster := &api.Cluster{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering template %s", t.Name)
+	}
+	err = moveMetadataLabels(cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering template %s", t.Name)
+	}
+
+	apiVersion := t.APIVersion
+	if apiVersion == "" {
+		apiVersion = "yap.pseudonator.io/v1alpha1"
+	}
+	cluster.TypeMeta = api.TypeMeta{Kind: "Cluster", APIVersion: apiVersion}
+	return cluster, nil
+}
+
+// Resolves the value of every parameter.
+func templateParams(t *api.ClusterTemplate, values map[string]string) (map[string]string, error) {
+	declared := make(map[string]bool, len(t.Parameters))
+	names := make([]string, 0, len(t.Parameters))
+	for _, p := range t.Parameters {
+		if p.Name == "" {
+			return nil, fmt.Errorf("template %s has a parameter with no name", t.Name)
+		}
+		if declared[p.Name] {
+			return nil, fmt.Errorf("template %s declares parameter %q more than once", t.Name, p.Name)
+		}
+		declared[p.Name] = true
+		names = append(names, p.Name)
+	}
+
+	unknown := []string{}
+	for name := range values {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		declaredList := strings.Join(names, ", ")
+		if declaredList == "" {
+			declaredList = "<none>"
+		}
+		return nil, fmt.Errorf("template %s has no parameter %s. Parameters: %s",
+			t.Name, strings.Join(unknown, ", "), declaredList)
+	}
+
+	result := make(map[string]string, len(t.Parameters))
+	missing := []string{}
+	for _, p := range t.Parameters {
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Required {
+				missing = append(missing, p.Name)
+				continue
+			}
+			value = p.Default
+		}
+		result[p.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("template %s requires parameters: %s. Set them with --set NAME=VALUE",
+			t.Name, strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+type templateRenderer struct {
+	params map[string]string
+}
+
+// Substitutes parameters in every scalar, then expands repeated list items.
+func (r templateRenderer) render(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return r.renderScalar(node)
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			// Keys are never parameterized.
+			err := r.render(node.Content[i+1])
+			if err != nil {
+				return err
+			}
+		}
+
+	case yaml.SequenceNode:
+		items := make([]*yaml.Node, 0, len(node.Content))
+		for _, item := range node.Content {
+			err := r.render(item)
+			if err != nil {
+				return err
+			}
+
+			count, ok, err := takeRepeatCount(item)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				items = append(items, item)
+				continue
+			}
+			for i := 0; i < count; i++ {
+				items = append(items, cloneNode(item))
+			}
+		}
+		node.Content = items
+
+	case yaml.DocumentNode, yaml.AliasNode:
+		for _, child := range node.Content {
+			err := r.render(child)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r templateRenderer) renderScalar(node *yaml.Node) error {
+	if !strings.Contains(node.Value, "$") {
+		return nil
+	}
+
+	// A value that's only a reference takes on the type of the parameter
+	// value, so that `minCPUs: $(cpus)` renders to a number.
+	match := templateRefRE.FindStringSubmatch(node.Value)
+	if match != nil && match[0] == node.Value && match[1] != "" {
+		value, ok := r.params[match[1]]
+		if !ok {
+			return fmt.Errorf("undefined parameter %q", match[1])
+		}
+		node.Value = value
+		node.Tag = ""
+		node.Style = 0
+		return nil
+	}
+
+	var err error
+	value := templateRefRE.ReplaceAllStringFunc(node.Value, func(ref string) string {
+		if ref == "$$(" {
+			return "$("
+		}
+		name := ref[2 : len(ref)-1]
+		value, ok := r.params[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined parameter %q", name)
+		}
+		return value
+	})
+	if err != nil {
+		return err
+	}
+	node.Value = value
+	node.Tag = "!!str"
+	node.Style = 0
+	return nil
+}
+
+// Removes the repeat key from a list item, and returns its count.
+func takeRepeatCount(item *yaml.Node) (int, bool, error) {
+	if item.Kind != yaml.MappingNode {
+		return 0, false, nil
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value != templateRepeatKey {
+			continue
+		}
+
+		value := item.Content[i+1].Value
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return 0, false, fmt.Errorf("%s must be a non-negative integer, got %q", templateRepeatKey, value)
+		}
+		item.Content = append(item.Content[:i], item.Content[i+2:]...)
+		return count, true, nil
+	}
+	return 0, false, nil
+}
+
+func cloneNode(node *yaml.Node) *yaml.Node {
+	out := *node
+	if node.Content != nil {
+		out.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			out.Content[i] = cloneNode(child)
+		}
+	}
+	return &out
+}
@@ -0,0 +1,128 @@
+package encoding
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+const teamKindTemplate = `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterTemplate
+name: team-kind
+parameters:
+- name: name
+  default: kind-team
+- name: workers
+  default: "1"
+- name: cpus
+  required: true
+template:
+  product: kind
+  name: $(name)
+  minCPUs: $(cpus)
+  labels:
+    team: payments-$(name)
+    escaped: $$(name)
+  kindV1Alpha4Cluster:
+    nodes:
+    - role: control-plane
+    - role: worker
+      yap.pseudonator.io/repeat: $(workers)
+`
+
+func parseTemplate(t *testing.T, yaml string) *api.ClusterTemplate {
+	objs, err := ParseStream(strings.NewReader(yaml))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(objs))
+	return objs[0].(*api.ClusterTemplate)
+}
+
+func TestRenderClusterTemplate(t *testing.T) {
+	tmpl := parseTemplate(t, teamKindTemplate)
+	assert.Equal(t, "team-kind", tmpl.Name)
+
+	cluster, err := RenderClusterTemplate(tmpl, map[string]string{"workers": "2", "cpus": "4"})
+	require.NoError(t, err)
+	assert.Equal(t, api.TypeMeta{Kind: "Cluster", APIVersion: "yap.pseudonator.io/v1alpha1"}, cluster.TypeMeta)
+	assert.Equal(t, "kind-team", cluster.Name)
+	assert.Equal(t, "kind", cluster.Product)
+	assert.Equal(t, 4, cluster.MinCPUs)
+	assert.Equal(t, map[string]string{"team": "payments-kind-team", "escaped": "$(name)"}, cluster.Labels)
+
+	require.NotNil(t, cluster.KindV1Alpha4Cluster)
+	roles := []string{}
+	for _, node := range cluster.KindV1Alpha4Cluster.Nodes {
+		roles = append(roles, string(node.Role))
+	}
+	assert.Equal(t, []string{"control-plane", "worker", "worker"}, roles)
+
+	// Rendering doesn't modify the template.
+	rendered, err := RenderClusterTemplate(tmpl, map[string]string{"workers": "0", "cpus": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(rendered.KindV1Alpha4Cluster.Nodes))
+}
+
+// Parameter values that look like numbers still work in string fields.
+func TestRenderClusterTemplateStringValue(t *testing.T) {
+	tmpl := parseTemplate(t, teamKindTemplate)
+	cluster, err := RenderClusterTemplate(tmpl, map[string]string{"name": "007", "cpus": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "007", cluster.Name)
+}
+
+func TestRenderClusterTemplateErrors(t *testing.T) {
+	tmpl := parseTemplate(t, teamKindTemplate)
+
+	for _, tc := range []struct {
+		name     string
+		values   map[string]string
+		expected string
+	}{
+		{"missing", map[string]string{}, "template team-kind requires parameters: cpus"},
+		{"unknown", map[string]string{"cpus": "1", "zones": "2"}, "template team-kind has no parameter zones. Parameters: name, workers, cpus"},
+		{"bad repeat", map[string]string{"cpus": "1", "workers": "two"}, `yap.pseudonator.io/repeat must be a non-negative integer, got "two"`},
+		{"bad type", map[string]string{"cpus": "lots"}, "cannot unmarshal !!str `lots` into int"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := RenderClusterTemplate(tmpl, tc.values)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestRenderClusterTemplateUndefined(t *testing.T) {
+	tmpl := parseTemplate(t, `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterTemplate
+name: broken
+template:
+  product: kind
+  name: kind-$(team)
+`)
+	_, err := RenderClusterTemplate(tmpl, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `rendering template broken: undefined parameter "team"`)
+	}
+}
+
+func TestRenderClusterTemplateTypo(t *testing.T) {
+	tmpl := parseTemplate(t, `
+apiVersion: yap.pseudonator.io/v1alpha1
+kind: ClusterTemplate
+name: typo
+template:
+  product: kind
+  nameTypo: kind-kind
+`)
+	_, err := RenderClusterTemplate(tmpl, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "field nameTypo not found in type api.Cluster")
+	}
+}
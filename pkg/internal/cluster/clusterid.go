@@ -39,6 +39,7 @@ const (
 	ProductRancherDesktop Product = "rancher-desktop"
 	ProductColima         Product = "colima"
 	ProductEKS            Product = "eks"
+	ProductAKS            Product = "aks"
 )
 
 func (p Product) IsDevCluster() bool {
@@ -53,6 +54,12 @@ func (p Product) IsDevCluster() bool {
 		p == ProductColima
 }
 
+// Cloud clusters run on a hosted control plane, so they don't need a
+// local Docker machine.
+func (p Product) IsCloudCluster() bool {
+	return p == ProductEKS || p == ProductGKE || p == ProductAKS
+}
+
 func ProductFromContext(c *clientcmdapi.Context, cl *clientcmdapi.Cluster) Product {
 	cn := c.Cluster
 	if strings.HasPrefix(cn, string(ProductMinikube)) {
@@ -90,6 +97,14 @@ func ProductFromContext(c *clientcmdapi.Context, cl *clientcmdapi.Cluster) Produ
 		return ProductColima
 	}
 
+	// `az aks get-credentials` names the context after the cluster, and lets
+	// users pick their own name with --context. But the apiserver is always
+	// hosted on azmk8s.io, e.g.,
+	// https://[dns-prefix]-[hash].hcp.eastus.azmk8s.io:443
+	if cl != nil && strings.Contains(cl.Server, ".azmk8s.io") {
+		return ProductAKS
+	}
+
 	loc := c.LocationOfOrigin
 	homedir, err := homedir.Dir()
 	if err != nil {
@@ -0,0 +1,177 @@
+package printers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+var jsonRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+// RelaxedJSONPathExpression accepts the same JSONPath shorthand as kubectl,
+// so that '.status.cpus', 'status.cpus', and '{.status.cpus}' all work.
+func RelaxedJSONPathExpression(pathExpression string) (string, error) {
+	if len(pathExpression) == 0 {
+		return pathExpression, nil
+	}
+	submatches := jsonRegexp.FindStringSubmatch(pathExpression)
+	if submatches == nil {
+		return "", fmt.Errorf("unexpected path string, expected a 'name1.name2' or '.name1.name2' or '{name1.name2}' or '{.name1.name2}'")
+	}
+	if len(submatches) != 3 {
+		return "", fmt.Errorf("unexpected submatch list: %v", submatches)
+	}
+	var fieldSpec string
+	if len(submatches[1]) != 0 {
+		fieldSpec = submatches[1]
+	} else {
+		fieldSpec = submatches[2]
+	}
+	return fmt.Sprintf("{.%s}", fieldSpec), nil
+}
+
+// Column is a single column of custom-columns output.
+type Column struct {
+	Header string
+
+	// A JSONPath expression, relative to each object.
+	FieldSpec string
+}
+
+// ParseCustomColumns parses a spec like 'NAME:.name,CPUS:.status.cpus'.
+func ParseCustomColumns(spec string) ([]Column, error) {
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("custom-columns format specified but no custom columns given")
+	}
+	parts := strings.Split(spec, ",")
+	columns := make([]Column, len(parts))
+	for i, part := range parts {
+		colSpec := strings.SplitN(part, ":", 2)
+		if len(colSpec) != 2 || colSpec[0] == "" {
+			return nil, fmt.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", part)
+		}
+		fieldSpec, err := RelaxedJSONPathExpression(colSpec[1])
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = Column{Header: colSpec[0], FieldSpec: fieldSpec}
+	}
+	return columns, nil
+}
+
+// CustomColumnsPrinter prints one row per object, with a column for
+// each JSONPath expression.
+type CustomColumnsPrinter struct {
+	Columns   []Column
+	NoHeaders bool
+
+	parsers []*jsonpath.JSONPath
+}
+
+func NewCustomColumnsPrinter(columns []Column, noHeaders bool) (*CustomColumnsPrinter, error) {
+	parsers := make([]*jsonpath.JSONPath, len(columns))
+	for i, c := range columns {
+		p := jsonpath.New(fmt.Sprintf("column%d", i)).AllowMissingKeys(true)
+		err := p.Parse(c.FieldSpec)
+		if err != nil {
+			return nil, fmt.Errorf("parsing column %s: %v", c.Header, err)
+		}
+		parsers[i] = p
+	}
+	return &CustomColumnsPrinter{Columns: columns, NoHeaders: noHeaders, parsers: parsers}, nil
+}
+
+func (p *CustomColumnsPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+
+	if !p.NoHeaders {
+		headers := make([]string, len(p.Columns))
+		for i, c := range p.Columns {
+			headers[i] = c.Header
+		}
+		_, err := fmt.Fprintln(w, strings.Join(headers, "\t"))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range ListItems(obj) {
+		err := p.printOne(item, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *CustomColumnsPrinter) printOne(obj interface{}, w io.Writer) error {
+	data, err := ToJSONObject(obj)
+	if err != nil {
+		return err
+	}
+
+	cells := make([]string, len(p.parsers))
+	for i, parser := range p.parsers {
+		values, err := parser.FindResults(data)
+		if err != nil {
+			return err
+		}
+
+		strs := []string{}
+		for _, result := range values {
+			for _, v := range result {
+				strs = append(strs, fmt.Sprintf("%v", v.Interface()))
+			}
+		}
+		if len(strs) == 0 {
+			cells[i] = "<none>"
+		} else {
+			cells[i] = strings.Join(strs, ",")
+		}
+	}
+	_, err = fmt.Fprintln(w, strings.Join(cells, "\t"))
+	return err
+}
+
+// ListItems returns the items of a yap list, or the object itself.
+func ListItems(obj runtime.Object) []interface{} {
+	switch o := obj.(type) {
+	case *api.ClusterList:
+		items := make([]interface{}, len(o.Items))
+		for i := range o.Items {
+			items[i] = &o.Items[i]
+		}
+		return items
+	case *api.RegistryList:
+		items := make([]interface{}, len(o.Items))
+		for i := range o.Items {
+			items[i] = &o.Items[i]
+		}
+		return items
+	}
+	return []interface{}{obj}
+}
+
+// ToJSONObject converts an object to generic maps and slices, so that
+// JSONPath expressions use the JSON field names.
+func ToJSONObject(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package printers
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+// SortItems sorts the items of a yap list in place by the value at a
+// JSONPath expression. Items missing the value sort first. Other objects
+// are left alone.
+func SortItems(obj runtime.Object, sortBy string) error {
+	if sortBy == "" {
+		return nil
+	}
+
+	fieldSpec, err := RelaxedJSONPathExpression(sortBy)
+	if err != nil {
+		return err
+	}
+
+	parser := jsonpath.New("sorting").AllowMissingKeys(true)
+	err = parser.Parse(fieldSpec)
+	if err != nil {
+		return fmt.Errorf("parsing --sort-by: %v", err)
+	}
+
+	items := ListItems(obj)
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		keys[i], err = sortKey(parser, item)
+		if err != nil {
+			return err
+		}
+	}
+
+	less := func(i, j int) bool { return lessValue(keys[i], keys[j]) }
+	swap := func(i, j int) { keys[i], keys[j] = keys[j], keys[i] }
+
+	switch o := obj.(type) {
+	case *api.ClusterList:
+		sort.Stable(sorter{len(o.Items), less, func(i, j int) {
+			swap(i, j)
+			o.Items[i], o.Items[j] = o.Items[j], o.Items[i]
+		}})
+	case *api.RegistryList:
+		sort.Stable(sorter{len(o.Items), less, func(i, j int) {
+			swap(i, j)
+			o.Items[i], o.Items[j] = o.Items[j], o.Items[i]
+		}})
+	}
+	return nil
+}
+
+func sortKey(parser *jsonpath.JSONPath, item interface{}) (interface{}, error) {
+	data, err := ToJSONObject(item)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parser.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return nil, nil
+	}
+	return results[0][0].Interface(), nil
+}
+
+// Orders JSON values: missing values first, then numbers, booleans, and
+// strings in their natural order. Mixed types compare by their string form.
+func lessValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return a < b
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return a < b
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			return !a && b
+		}
+	}
+	return fmt.Sprintf("%v", a) < fmt.Sprintf("%v", b)
+}
+
+type sorter struct {
+	n    int
+	less func(i, j int) bool
+	swap func(i, j int)
+}
+
+func (s sorter) Len() int           { return s.n }
+func (s sorter) Less(i, j int) bool { return s.less(i, j) }
+func (s sorter) Swap(i, j int)      { s.swap(i, j) }
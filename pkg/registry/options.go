@@ -0,0 +1,26 @@
+package registry
+
+import (
+	"k8s.io/apimachinery/pkg/fields"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+type ListOptions struct {
+	FieldSelector string
+}
+
+type registryFields api.Registry
+
+func (rf *registryFields) Has(field string) bool {
+	return field == "name"
+}
+
+func (rf *registryFields) Get(field string) string {
+	if field == "name" {
+		return (*api.Registry)(rf).Name
+	}
+	return ""
+}
+
+var _ fields.Fields = &registryFields{}
@@ -0,0 +1,257 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+	"github.com/pseudonator/yap/pkg/internal/dctr"
+)
+
+var typeMeta = api.TypeMeta{APIVersion: "yap.pseudonator.io/v1alpha1", Kind: "Registry"}
+var listTypeMeta = api.TypeMeta{APIVersion: "yap.pseudonator.io/v1alpha1", Kind: "RegistryList"}
+var groupResource = schema.GroupResource{Group: "yap.pseudonator.io", Resource: "registries"}
+
+// The image we use to run registries, and the filter we use to find them.
+const registryImage = "docker.io/library/registry:2"
+const registryImageFilter = "registry:2"
+
+// The port the registry listens on inside its own container.
+const registryContainerPort = 5000
+
+const defaultListenAddress = "127.0.0.1"
+
+func TypeMeta() api.TypeMeta {
+	return typeMeta
+}
+func ListTypeMeta() api.TypeMeta {
+	return listTypeMeta
+}
+
+func FillDefaults(registry *api.Registry) {
+	if registry.Name == "" {
+		registry.Name = "yap-registry"
+	}
+}
+
+type Controller struct {
+	iostreams    genericclioptions.IOStreams
+	dockerClient dctr.Client
+}
+
+func NewController(iostreams genericclioptions.IOStreams, dockerClient dctr.Client) *Controller {
+	return &Controller{
+		iostreams:    iostreams,
+		dockerClient: dockerClient,
+	}
+}
+
+func DefaultController(iostreams genericclioptions.IOStreams) (*Controller, error) {
+	dockerClient, err := dctr.NewAPIClient(iostreams)
+	if err != nil {
+		return nil, err
+	}
+	return NewController(iostreams, dockerClient), nil
+}
+
+func (c *Controller) Get(ctx context.Context, name string) (*api.Registry, error) {
+	list, err := c.List(ctx, ListOptions{FieldSelector: fmt.Sprintf("name=%s", name)})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Items) == 0 {
+		return nil, apierrors.NewNotFound(groupResource, name)
+	}
+
+	item := list.Items[0]
+	return &item, nil
+}
+
+func (c *Controller) List(ctx context.Context, options ListOptions) (*api.RegistryList, error) {
+	selector, err := fields.ParseSelector(options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := c.dockerClient.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("ancestor", registryImageFilter)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []api.Registry{}
+	for _, container := range containers {
+		if len(container.Names) == 0 {
+			continue
+		}
+
+		name := strings.TrimPrefix(container.Names[0], "/")
+		ipAddress := ""
+		networks := []string{}
+		if container.NetworkSettings != nil {
+			for network, settings := range container.NetworkSettings.Networks {
+				networks = append(networks, network)
+				if network == "bridge" && settings != nil {
+					ipAddress = settings.IPAddress
+				}
+			}
+		}
+		sort.Strings(networks)
+
+		listenAddress, hostPort, containerPort := portsFrom(container.Ports)
+		registry := &api.Registry{
+			TypeMeta: typeMeta,
+			Name:     name,
+			Status: api.RegistryStatus{
+				CreationTimestamp: metav1.Time{Time: time.Unix(container.Created, 0)},
+				ContainerID:       container.ID,
+				IPAddress:         ipAddress,
+				HostPort:          hostPort,
+				ListenAddress:     listenAddress,
+				ContainerPort:     containerPort,
+				Networks:          networks,
+				State:             container.State,
+				Labels:            container.Labels,
+			},
+		}
+
+		if !selector.Matches((*registryFields)(registry)) {
+			continue
+		}
+		result = append(result, *registry)
+	}
+
+	return &api.RegistryList{
+		TypeMeta: listTypeMeta,
+		Items:    result,
+	}, nil
+}
+
+// Compare the desired registry against the existing registry, and reconcile
+// the two to match.
+func (c *Controller) Apply(ctx context.Context, desired *api.Registry) (*api.Registry, error) {
+	FillDefaults(desired)
+
+	existing, err := c.Get(ctx, desired.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if existing == nil {
+		existing = &api.Registry{}
+	}
+
+	listenAddress := desired.ListenAddress
+	if listenAddress == "" {
+		listenAddress = defaultListenAddress
+	}
+
+	if existing.Status.ContainerID != "" {
+		needsDelete := false
+		if desired.Port != 0 && desired.Port != existing.Status.HostPort {
+			_, _ = fmt.Fprintf(c.iostreams.ErrOut,
+				"Deleting registry %s because desired port (%d) does not match current (%d)\n",
+				desired.Name, desired.Port, existing.Status.HostPort)
+			needsDelete = true
+		} else if listenAddress != existing.Status.ListenAddress {
+			_, _ = fmt.Fprintf(c.iostreams.ErrOut,
+				"Deleting registry %s because desired listen address (%s) does not match current (%s)\n",
+				desired.Name, listenAddress, existing.Status.ListenAddress)
+			needsDelete = true
+		} else if !labelsMatch(desired.Labels, existing.Status.Labels) {
+			_, _ = fmt.Fprintf(c.iostreams.ErrOut,
+				"Deleting registry %s because desired labels do not match current\n", desired.Name)
+			needsDelete = true
+		}
+
+		if needsDelete {
+			err := c.Delete(ctx, desired.Name)
+			if err != nil {
+				return nil, err
+			}
+		} else if existing.Status.State == "running" {
+			return existing, nil
+		}
+	}
+
+	hostPort := ""
+	if desired.Port != 0 {
+		hostPort = strconv.Itoa(desired.Port)
+	}
+	containerPort := nat.Port(fmt.Sprintf("%d/tcp", registryContainerPort))
+
+	_, _ = fmt.Fprintf(c.iostreams.ErrOut, "Creating registry %q...\n", desired.Name)
+	err = dctr.Run(
+		ctx,
+		c.dockerClient,
+		desired.Name,
+		&container.Config{
+			Hostname:     desired.Name,
+			Image:        registryImage,
+			ExposedPorts: nat.PortSet{containerPort: struct{}{}},
+			Labels:       desired.Labels,
+		},
+		&container.HostConfig{
+			RestartPolicy: container.RestartPolicy{Name: "always"},
+			PortBindings: nat.PortMap{
+				containerPort: []nat.PortBinding{
+					{HostIP: listenAddress, HostPort: hostPort},
+				},
+			},
+		},
+		&network.NetworkingConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Get(ctx, desired.Name)
+}
+
+// Delete the registry container. The registry storage is lost.
+func (c *Controller) Delete(ctx context.Context, name string) error {
+	_, err := c.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	return dctr.RemoveIfNecessary(ctx, c.dockerClient, name)
+}
+
+// Finds the port binding of the registry port.
+//
+// Returns the listen address, host port, and container port.
+func portsFrom(ports []types.Port) (string, int, int) {
+	for _, port := range ports {
+		if port.PrivatePort == registryContainerPort && port.PublicPort != 0 {
+			return port.IP, int(port.PublicPort), int(port.PrivatePort)
+		}
+	}
+	return "", 0, 0
+}
+
+// Checks that all the desired labels are on the existing container.
+// Extra labels on the container are OK.
+func labelsMatch(desired, existing map[string]string) bool {
+	for k, v := range desired {
+		if existing[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,250 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/pseudonator/yap/pkg/api"
+)
+
+var createTime = time.Unix(1500000000, 0)
+
+func kindRegistry() types.Container {
+	return types.Container{
+		ID:      "a815c0ec15f1f7430bd402e3fffe65026dd692a1a99861a52b3e30ad6e253a08",
+		Names:   []string{"/kind-registry"},
+		Image:   "registry:2",
+		Created: createTime.Unix(),
+		Ports: []types.Port{
+			types.Port{IP: "127.0.0.1", PrivatePort: 5000, PublicPort: 5001, Type: "tcp"},
+		},
+		State: "running",
+		NetworkSettings: &types.SummaryNetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"bridge": &network.EndpointSettings{IPAddress: "172.0.1.2"},
+				"kind":   &network.EndpointSettings{IPAddress: "172.0.1.3"},
+			},
+		},
+	}
+}
+
+func TestListRegistries(t *testing.T) {
+	f := newFixture(t)
+	f.docker.containers = []types.Container{kindRegistry()}
+
+	list, err := f.c.List(context.Background(), ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []api.Registry{
+		api.Registry{
+			TypeMeta: typeMeta,
+			Name:     "kind-registry",
+			Status: api.RegistryStatus{
+				CreationTimestamp: metav1.Time{Time: createTime},
+				ContainerID:       "a815c0ec15f1f7430bd402e3fffe65026dd692a1a99861a52b3e30ad6e253a08",
+				IPAddress:         "172.0.1.2",
+				HostPort:          5001,
+				ListenAddress:     "127.0.0.1",
+				ContainerPort:     5000,
+				Networks:          []string{"bridge", "kind"},
+				State:             "running",
+			},
+		},
+	}, list.Items)
+}
+
+func TestGetRegistryNotFound(t *testing.T) {
+	f := newFixture(t)
+	f.docker.containers = []types.Container{kindRegistry()}
+
+	_, err := f.c.Get(context.Background(), "yap-registry")
+	if assert.Error(t, err) {
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
+
+func TestApplyRegistryExisting(t *testing.T) {
+	f := newFixture(t)
+	f.docker.containers = []types.Container{kindRegistry()}
+
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Port:     5001,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5001, registry.Status.HostPort)
+	assert.Nil(t, f.docker.lastCreateConfig)
+}
+
+func TestApplyRegistryNew(t *testing.T) {
+	f := newFixture(t)
+
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Port:     5002,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5002, registry.Status.HostPort)
+	assert.Equal(t, "docker.io/library/registry:2", f.docker.lastCreateConfig.Image)
+	assert.Equal(t, []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "5002"}},
+		f.docker.lastCreateHostConfig.PortBindings["5000/tcp"])
+	assert.Contains(t, f.errOut.String(), "Creating registry \"kind-registry\"")
+}
+
+func TestApplyRegistryChangePort(t *testing.T) {
+	f := newFixture(t)
+	f.docker.containers = []types.Container{kindRegistry()}
+
+	registry, err := f.c.Apply(context.Background(), &api.Registry{
+		TypeMeta: typeMeta,
+		Name:     "kind-registry",
+		Port:     5002,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5002, registry.Status.HostPort)
+	assert.Equal(t, []string{"kind-registry"}, f.docker.removed)
+	assert.Contains(t, f.errOut.String(),
+		"Deleting registry kind-registry because desired port (5002) does not match current (5001)")
+}
+
+func TestDeleteRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.docker.containers = []types.Container{kindRegistry()}
+
+	err := f.c.Delete(context.Background(), "kind-registry")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind-registry"}, f.docker.removed)
+
+	err = f.c.Delete(context.Background(), "kind-registry")
+	if assert.Error(t, err) {
+		assert.True(t, apierrors.IsNotFound(err))
+	}
+}
+
+type fixture struct {
+	t      *testing.T
+	c      *Controller
+	docker *fakeDocker
+	errOut *strings.Builder
+}
+
+func newFixture(t *testing.T) *fixture {
+	errOut := &strings.Builder{}
+	d := &fakeDocker{}
+	iostreams := genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: errOut}
+	return &fixture{
+		t:      t,
+		c:      NewController(iostreams, d),
+		docker: d,
+		errOut: errOut,
+	}
+}
+
+type fakeDocker struct {
+	containers           []types.Container
+	removed              []string
+	lastCreateConfig     *container.Config
+	lastCreateHostConfig *container.HostConfig
+}
+
+func (d *fakeDocker) DaemonHost() string {
+	return ""
+}
+
+func (d *fakeDocker) ImagePull(ctx context.Context, image string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (d *fakeDocker) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return d.containers, nil
+}
+
+func (d *fakeDocker) find(name string) (types.Container, bool) {
+	for _, c := range d.containers {
+		if len(c.Names) > 0 && strings.TrimPrefix(c.Names[0], "/") == name {
+			return c, true
+		}
+	}
+	return types.Container{}, false
+}
+
+func (d *fakeDocker) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	c, ok := d.find(containerID)
+	if !ok {
+		return types.ContainerJSON{}, errdefs.NotFound(io.EOF)
+	}
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    c.ID,
+			State: &types.ContainerState{Running: c.State == "running"},
+		},
+	}, nil
+}
+
+func (d *fakeDocker) ContainerRemove(ctx context.Context, id string, options types.ContainerRemoveOptions) error {
+	containers := []types.Container{}
+	for _, c := range d.containers {
+		if c.ID == id || (len(c.Names) > 0 && strings.TrimPrefix(c.Names[0], "/") == id) {
+			d.removed = append(d.removed, strings.TrimPrefix(c.Names[0], "/"))
+			continue
+		}
+		containers = append(containers, c)
+	}
+	d.containers = containers
+	return nil
+}
+
+func (d *fakeDocker) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	d.lastCreateConfig = config
+	d.lastCreateHostConfig = hostConfig
+
+	ports := []types.Port{}
+	for port, bindings := range hostConfig.PortBindings {
+		for _, b := range bindings {
+			publicPort, _ := nat.ParsePort(b.HostPort)
+			ports = append(ports, types.Port{
+				IP:          b.HostIP,
+				PrivatePort: uint16(port.Int()),
+				PublicPort:  uint16(publicPort),
+				Type:        port.Proto(),
+			})
+		}
+	}
+
+	d.containers = append(d.containers, types.Container{
+		ID:      containerName + "-id",
+		Names:   []string{"/" + containerName},
+		Image:   config.Image,
+		Created: createTime.Unix(),
+		Ports:   ports,
+		State:   "created",
+		Labels:  config.Labels,
+	})
+	return container.ContainerCreateCreatedBody{ID: containerName + "-id"}, nil
+}
+
+func (d *fakeDocker) ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error {
+	for i, c := range d.containers {
+		if c.ID == containerID {
+			d.containers[i].State = "running"
+		}
+	}
+	return nil
+}
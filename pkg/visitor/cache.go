@@ -0,0 +1,132 @@
+package visitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// An on-disk cache of a URL's last successful response.
+//
+// Responses may contain private configs, so only the user can read them.
+type urlCache struct {
+	// Empty if caching is disabled.
+	path string
+
+	// The metadata of a response that should replace the cached one.
+	meta    cacheMeta
+	updated bool
+}
+
+type cacheMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+
+	// When the server last sent or confirmed this response.
+	Validated time.Time `json:"validated"`
+}
+
+type cachedResponse struct {
+	cacheMeta
+	body []byte
+
+	// When we last wrote the body. Entries from before we recorded
+	// Validated use this instead.
+	modTime time.Time
+}
+
+// When the server last sent or confirmed the response.
+func (r *cachedResponse) validated() time.Time {
+	if r.Validated.IsZero() {
+		return r.modTime
+	}
+	return r.Validated
+}
+
+func newURLCache(dir string, u *url.URL) *urlCache {
+	if dir == "" {
+		return &urlCache{}
+	}
+	key := sha256.Sum256([]byte(u.String()))
+	return &urlCache{path: filepath.Join(dir, hex.EncodeToString(key[:]))}
+}
+
+// Returns the cached response, or nil if there isn't one.
+func (c *urlCache) read() *cachedResponse {
+	if c.path == "" {
+		return nil
+	}
+	metaJSON, err := os.ReadFile(c.path + ".json")
+	if err != nil {
+		return nil
+	}
+	body, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return nil
+	}
+
+	result := &cachedResponse{body: body, modTime: info.ModTime()}
+	err = json.Unmarshal(metaJSON, &result.cacheMeta)
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+func (c *urlCache) update(meta cacheMeta) {
+	c.meta = meta
+	c.updated = true
+}
+
+func (c *urlCache) write(body []byte) error {
+	if c.path == "" {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(c.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	metaJSON, err := json.Marshal(c.meta)
+	if err != nil {
+		return err
+	}
+
+	// Write the body first, so that a reader never sees
+	// new metadata with an old body.
+	err = writeFileAtomic(c.path, body)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.path+".json", metaJSON)
+}
+
+func writeFileAtomic(path string, contents []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(contents)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
@@ -8,9 +8,18 @@ import (
 )
 
 func DecodeAll(vs []Interface) ([]runtime.Object, error) {
+	return DecodeAllWithOptions(vs, encoding.ParseOptions{})
+}
+
+func Decode(v Interface) ([]runtime.Object, error) {
+	return DecodeWithOptions(v, encoding.ParseOptions{})
+}
+
+// Decodes all the visitors, rewriting each stream as the options ask.
+func DecodeAllWithOptions(vs []Interface, opts encoding.ParseOptions) ([]runtime.Object, error) {
 	result := []runtime.Object{}
 	for _, v := range vs {
-		objs, err := Decode(v)
+		objs, err := DecodeWithOptions(v, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -19,14 +28,14 @@ func DecodeAll(vs []Interface) ([]runtime.Object, error) {
 	return result, nil
 }
 
-func Decode(v Interface) ([]runtime.Object, error) {
+func DecodeWithOptions(v Interface, opts encoding.ParseOptions) ([]runtime.Object, error) {
 	r, err := v.Open()
 	if err != nil {
 		return nil, err
 	}
 	defer r.Close()
 
-	result, err := encoding.ParseStream(r)
+	result, err := encoding.ParseStreamWithOptions(r, opts)
 	if err != nil {
 		return nil, errors.Wrapf(err, "visiting %s", v.Name())
 	}
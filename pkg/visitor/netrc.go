@@ -0,0 +1,81 @@
+package visitor
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Finds the login and password for a host in a netrc file.
+//
+// Uses the first `machine` entry for the host, or the `default` entry
+// if there isn't one.
+func netrcCredentials(path, host string) (login string, password string, ok bool, err error) {
+	if path == "" {
+		return "", "", false, nil
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", false, nil
+		}
+		return "", "", false, errors.Wrap(err, "reading netrc")
+	}
+
+	tokens := netrcTokens(string(contents))
+	matched := false
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine", "default":
+			if matched {
+				return login, password, true, nil
+			}
+			if tokens[i] == "default" {
+				matched = true
+				continue
+			}
+			i++
+			matched = i < len(tokens) && tokens[i] == host
+
+		case "login", "password", "account":
+			key := tokens[i]
+			i++
+			if !matched || i >= len(tokens) {
+				continue
+			}
+			switch key {
+			case "login":
+				login = tokens[i]
+			case "password":
+				password = tokens[i]
+			}
+		}
+	}
+	return login, password, matched, nil
+}
+
+// Splits a netrc file into tokens, dropping comments and macros.
+func netrcTokens(contents string) []string {
+	tokens := []string{}
+	inMacro := false
+	for _, line := range strings.Split(contents, "\n") {
+		fields := strings.Fields(line)
+		if inMacro {
+			// A macro ends at a blank line.
+			inMacro = len(fields) > 0
+			continue
+		}
+		for _, field := range fields {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			if field == "macdef" {
+				inMacro = true
+				break
+			}
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
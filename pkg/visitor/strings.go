@@ -1,16 +1,38 @@
 package visitor
 
 import (
+	"fmt"
 	"io"
-	"net/http"
+	"io/fs"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
-func FromStrings(filenames []string, stdin io.Reader) ([]Interface, error) {
+// File extensions that we read from directories and globs.
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// Creates visitors for each -f argument.
+//
+// An argument may be "-" for stdin, an http(s) URL, a file, a directory,
+// or a glob. Directories and globs only match files with a manifest
+// extension, and visit them in lexical order. When recursive is set,
+// directories include their subdirectories.
+func FromStrings(filenames []string, stdin io.Reader, recursive bool) ([]Interface, error) {
 	result := []Interface{}
+	seen := make(map[string]bool)
+	addFile := func(path string) {
+		clean := filepath.Clean(path)
+		if seen[clean] {
+			return
+		}
+		seen[clean] = true
+		result = append(result, File(path))
+	}
+
 	for _, f := range filenames {
 
 		switch {
@@ -18,16 +40,113 @@ func FromStrings(filenames []string, stdin io.Reader) ([]Interface, error) {
 			result = append(result, Stdin(stdin))
 
 		case strings.Index(f, "http://") == 0 || strings.Index(f, "https://") == 0:
-			url, err := url.Parse(f)
+			u, err := url.Parse(f)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid URL %s", f)
+			}
+			_, err = checksumPin(u)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, URL(defaultHTTPClient, f))
+
+		case isGlob(f):
+			matches, err := filepath.Glob(f)
 			if err != nil {
-				return nil, errors.Wrapf(err, "invalid URL %s", url)
+				return nil, errors.Wrapf(err, "invalid pattern %s", f)
+			}
+
+			count := 0
+			for _, match := range matches {
+				info, err := os.Stat(match)
+				if err != nil {
+					return nil, err
+				}
+				files := []string{match}
+				if info.IsDir() {
+					files, err = dirFiles(match, recursive)
+					if err != nil {
+						return nil, err
+					}
+				} else if !hasManifestExtension(match) {
+					continue
+				}
+				for _, file := range files {
+					addFile(file)
+				}
+				count += len(files)
+			}
+			if count == 0 {
+				return nil, fmt.Errorf("no %s files match %s", extensionList(), f)
 			}
-			result = append(result, URL(http.DefaultClient, f))
 
 		default:
-			result = append(result, File(f))
+			info, err := os.Stat(f)
+			if err != nil || !info.IsDir() {
+				// Let Open() report a missing file, so that the error
+				// comes from the visitor that failed.
+				addFile(f)
+				continue
+			}
+
+			files, err := dirFiles(f, recursive)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				hint := ""
+				if !recursive {
+					hint = ". Use -R to include subdirectories"
+				}
+				return nil, fmt.Errorf("no %s files in directory %s%s", extensionList(), f, hint)
+			}
+			for _, file := range files {
+				addFile(file)
+			}
 
 		}
 	}
 	return result, nil
 }
+
+// Lists the manifests in a directory, in lexical order.
+func dirFiles(dir string, recursive bool) ([]string, error) {
+	result := []string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if hasManifestExtension(path) {
+			result = append(result, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading directory %s", dir)
+	}
+	return result, nil
+}
+
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+func hasManifestExtension(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range manifestExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func extensionList() string {
+	return strings.Join(manifestExtensions, ", ")
+}
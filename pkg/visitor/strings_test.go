@@ -0,0 +1,94 @@
+package visitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("apiVersion: yap.pseudonator.io/v1alpha1\nkind: Cluster\nproduct: kind\n"), 0644))
+	}
+}
+
+func visitorNames(vs []Interface) []string {
+	names := []string{}
+	for _, v := range vs {
+		names = append(names, v.Name())
+	}
+	return names
+}
+
+func TestFromStringsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "b.yml", "a.yaml", "c.json", "README.md", "nested/d.yaml")
+
+	vs, err := FromStrings([]string{dir}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.yaml"),
+		filepath.Join(dir, "b.yml"),
+		filepath.Join(dir, "c.json"),
+	}, visitorNames(vs))
+
+	vs, err = FromStrings([]string{dir}, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.yaml"),
+		filepath.Join(dir, "b.yml"),
+		filepath.Join(dir, "c.json"),
+		filepath.Join(dir, "nested", "d.yaml"),
+	}, visitorNames(vs))
+}
+
+func TestFromStringsEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "README.md", "nested/a.yaml")
+
+	_, err := FromStrings([]string{dir}, nil, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no .yaml, .yml, .json files in directory")
+		assert.Contains(t, err.Error(), "Use -R to include subdirectories")
+	}
+}
+
+func TestFromStringsGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "kind.yaml", "k3d.yaml", "notes.txt")
+
+	vs, err := FromStrings([]string{
+		filepath.Join(dir, "*"),
+		filepath.Join(dir, "kind.yaml"),
+	}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "k3d.yaml"),
+		filepath.Join(dir, "kind.yaml"),
+	}, visitorNames(vs))
+
+	_, err = FromStrings([]string{filepath.Join(dir, "*.json")}, nil, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "no .yaml, .yml, .json files match")
+	}
+}
+
+func TestDecodeErrorNamesFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.yaml")
+	bad := filepath.Join(dir, "b.yaml")
+	require.NoError(t, os.WriteFile(bad, []byte("apiVersion: yap.pseudonator.io/v1alpha1\nkind: Garbage\n"), 0644))
+
+	vs, err := FromStrings([]string{dir}, nil, false)
+	require.NoError(t, err)
+
+	_, err = DecodeAll(vs)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "visiting "+bad+": document 1, line 1, column 1: yap config must contain")
+	}
+}
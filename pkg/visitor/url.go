@@ -0,0 +1,290 @@
+package visitor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// How long we wait for a server before giving up, or falling back
+// to the cache.
+const defaultHTTPTimeout = 30 * time.Second
+
+var defaultHTTPClient = &http.Client{Timeout: defaultHTTPTimeout}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options for fetching URLs.
+type URLOptions struct {
+	// Where to cache responses, so that we can revalidate them with
+	// If-None-Match and read them when the server is unreachable.
+	// Empty disables the cache.
+	CacheDir string
+
+	// Path to a netrc file with credentials for each host.
+	// Empty disables netrc.
+	NetrcPath string
+
+	// Looks up credentials in the environment. Defaults to os.LookupEnv.
+	LookupEnv func(name string) (string, bool)
+
+	// Where to warn that we fell back to the cache. Defaults to os.Stderr.
+	Warnings io.Writer
+}
+
+// Caches responses in ~/.yap/cache/http, and reads credentials
+// from $NETRC or ~/.netrc.
+func DefaultURLOptions() URLOptions {
+	opts := URLOptions{LookupEnv: os.LookupEnv}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		opts.CacheDir = filepath.Join(home, ".yap", "cache", "http")
+		opts.NetrcPath = filepath.Join(home, ".netrc")
+	}
+	netrc := os.Getenv("NETRC")
+	if netrc != "" {
+		opts.NetrcPath = netrc
+	}
+	return opts
+}
+
+func URL(client HTTPClient, url string) urlVisitor {
+	return URLWithOptions(client, url, DefaultURLOptions())
+}
+
+// Visits a URL.
+//
+// Requests authenticate with, in order of preference: credentials in the
+// URL, a bearer token in YAP_HTTP_TOKEN_<HOST>, USER:PASSWORD in
+// YAP_HTTP_BASIC_AUTH_<HOST>, or the host's entry in the netrc file.
+// HOST is the URL host, upper-cased, with every other character replaced by
+// an underscore, like YAP_HTTP_TOKEN_ARTIFACTS_EXAMPLE_COM.
+//
+// A URL may pin its content with a #sha256=HEX fragment.
+func URLWithOptions(client HTTPClient, url string, opts URLOptions) urlVisitor {
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+	if opts.Warnings == nil {
+		opts.Warnings = os.Stderr
+	}
+	return urlVisitor{client: client, url: url, opts: opts}
+}
+
+type urlVisitor struct {
+	client HTTPClient
+	url    string
+	opts   URLOptions
+}
+
+func (v urlVisitor) Name() string {
+	u, err := url.Parse(v.url)
+	if err != nil {
+		return v.url
+	}
+	return u.Redacted()
+}
+
+func (v urlVisitor) Open() (io.ReadCloser, error) {
+	u, err := url.Parse(v.url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL %s", v.url)
+	}
+	pin, err := checksumPin(u)
+	if err != nil {
+		return nil, err
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	cache := newURLCache(v.opts.CacheDir, u)
+	cached := cache.read()
+
+	// Pinned content can't change, so there's no need to ask the server.
+	if pin != "" && cached != nil && checksum(cached.body) == pin {
+		return io.NopCloser(bytes.NewReader(cached.body)), nil
+	}
+
+	body, err := v.fetch(u, cache, cached)
+	if err != nil {
+		return nil, err
+	}
+
+	if pin != "" {
+		actual := checksum(body)
+		if actual != pin {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", v.Name(), pin, actual)
+		}
+	}
+
+	if cache.updated {
+		// The cache is an optimization. Failing to write it
+		// shouldn't fail the command.
+		_ = cache.write(body)
+	}
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+// Fetches the body, revalidating the cached response if we have one.
+//
+// Falls back to the cached response if the server is unreachable or
+// failing, but not if it rejects the request.
+func (v urlVisitor) fetch(u *url.URL, cache *urlCache, cached *cachedResponse) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", v.Name())
+	}
+	err = v.authorize(req, u)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		if cached != nil {
+			v.warnStale(cached, err.Error())
+			return cached.body, nil
+		}
+		return nil, errors.Wrapf(err, "fetching %s", v.Name())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		meta := cached.cacheMeta
+		meta.Validated = time.Now()
+		cache.update(meta)
+		return cached.body, nil
+
+	case resp.StatusCode >= 500 && cached != nil:
+		v.warnStale(cached, resp.Status)
+		return cached.body, nil
+
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, v.statusError(u, resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", v.Name())
+	}
+	cache.update(cacheMeta{
+		URL:          v.Name(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Validated:    time.Now(),
+	})
+	return body, nil
+}
+
+// Warns that we're reading a cached copy, because the server
+// couldn't tell us whether it's current.
+func (v urlVisitor) warnStale(cached *cachedResponse, reason string) {
+	age := duration.HumanDuration(time.Since(cached.validated()))
+	_, _ = fmt.Fprintf(v.opts.Warnings, "Warning: fetching %s failed (%s). "+
+		"Using the cached copy, which the server last confirmed %s ago\n",
+		v.Name(), reason, age)
+}
+
+func (v urlVisitor) authorize(req *http.Request, u *url.URL) error {
+	// net/http sends credentials in the URL itself.
+	if u.User != nil {
+		return nil
+	}
+
+	host := envHost(u.Host)
+	token, _ := v.opts.LookupEnv("YAP_HTTP_TOKEN_" + host)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	basic, _ := v.opts.LookupEnv("YAP_HTTP_BASIC_AUTH_" + host)
+	if basic != "" {
+		user, password, _ := strings.Cut(basic, ":")
+		req.SetBasicAuth(user, password)
+		return nil
+	}
+
+	login, password, ok, err := netrcCredentials(v.opts.NetrcPath, u.Hostname())
+	if err != nil {
+		return err
+	}
+	if ok {
+		req.SetBasicAuth(login, password)
+	}
+	return nil
+}
+
+func (v urlVisitor) statusError(u *url.URL, resp *http.Response) error {
+	msg := fmt.Sprintf("fetching %s: %s", v.Name(), resp.Status)
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	text, _, _ := strings.Cut(strings.TrimSpace(string(snippet)), "\n")
+	if text != "" {
+		msg += ": " + text
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		host := envHost(u.Host)
+		msg += fmt.Sprintf("\nTo authenticate, set YAP_HTTP_TOKEN_%s, set YAP_HTTP_BASIC_AUTH_%s=USER:PASSWORD, "+
+			"or add %s to your netrc file", host, host, u.Hostname())
+	}
+	return errors.New(msg)
+}
+
+// Converts a host to the suffix of an environment variable name.
+func envHost(host string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, host)
+}
+
+// Returns the checksum in a #sha256=HEX fragment, or empty
+// if the URL isn't pinned.
+func checksumPin(u *url.URL) (string, error) {
+	pin, ok := strings.CutPrefix(u.Fragment, "sha256=")
+	if !ok {
+		return "", nil
+	}
+	pin = strings.ToLower(pin)
+	_, err := hex.DecodeString(pin)
+	if err != nil || len(pin) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum in %s: expected #sha256= followed by 64 hex characters", u.Redacted())
+	}
+	return pin, nil
+}
+
+func checksum(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+var _ Interface = urlVisitor{}
@@ -1,9 +1,7 @@
 package visitor
 
 import (
-	"fmt"
 	"io"
-	"net/http"
 	"os"
 )
 
@@ -54,34 +52,4 @@ func (v fileVisitor) Open() (io.ReadCloser, error) {
 	return os.Open(v.path)
 }
 
-var _ Interface = urlVisitor{}
-
-type HTTPClient interface {
-	Get(url string) (*http.Response, error)
-}
-
-func URL(client HTTPClient, url string) urlVisitor {
-	return urlVisitor{client: client, url: url}
-}
-
-type urlVisitor struct {
-	client HTTPClient
-	url    string
-}
-
-func (v urlVisitor) Name() string {
-	return v.url
-}
-
-func (v urlVisitor) Open() (io.ReadCloser, error) {
-	resp, err := v.client.Get(v.url)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetch(%q) failed with status code %d", v.url, resp.StatusCode)
-	}
-	return resp.Body, nil
-}
-
-var _ Interface = urlVisitor{}
+var _ Interface = fileVisitor{}